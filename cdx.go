@@ -0,0 +1,354 @@
+package archiveorg
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/ioutil"
+	"iter"
+	"math/bits"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	cdxApi string = "https://web.archive.org/cdx/search/cdx"
+)
+
+type HostStats struct {
+	Host string
+	// Number of distinct URLs captured.
+	URLs int
+	// Number of captures across all URLs.
+	Captures     int
+	FirstCapture time.Time
+	LastCapture  time.Time
+}
+
+// Gets capture statistics for every URL on a host, such as how many
+// URLs have been captured and when the first and last captures were.
+// Large hosts can have millions of captures, so this can take a while.
+// Does not need to be authenticated.
+func GetHostStats(host string, opts ...CallOption) (s HostStats, err error) {
+	return defaultClient.GetHostStats(host, opts...)
+}
+
+// GetHostStats is the Client version of GetHostStats.
+func (c *Client) GetHostStats(host string, opts ...CallOption) (s HostStats, err error) {
+	return c.getCDXStats(host, "host", opts)
+}
+
+// Like GetHostStats, but also includes every subdomain of domain.
+// Does not need to be authenticated.
+func GetDomainStats(domain string, opts ...CallOption) (s HostStats, err error) {
+	return defaultClient.GetDomainStats(domain, opts...)
+}
+
+// GetDomainStats is the Client version of GetDomainStats.
+func (c *Client) GetDomainStats(domain string, opts ...CallOption) (s HostStats, err error) {
+	return c.getCDXStats(domain, "domain", opts)
+}
+
+func (c *Client) getCDXStats(host string, matchType string, opts []CallOption) (s HostStats, err error) {
+	o := c.callOptions(opts)
+	ctx, cancel := o.context()
+	defer cancel()
+	params := url.Values{
+		"url":       {host},
+		"matchType": {matchType},
+		"fl":        {"urlkey,timestamp"},
+	}
+	body, err := c.queryCDX(ctx, o.retries, params)
+	if err != nil {
+		return s, err
+	}
+	defer body.Close()
+
+	s, err = hostStatsFromCDX(body)
+	if err != nil {
+		return s, err
+	}
+	s.Host = host
+	return s, nil
+}
+
+// queryCDX calls the CDX API, retrying up to retries times, and returns
+// the response body, which the caller must close.
+func (c *Client) queryCDX(ctx context.Context, retries uint, params url.Values) (body io.ReadCloser, err error) {
+	err = c.retry(ctx, retries, func() error {
+		resp, err := c.get(ctx, cdxApi+"?"+params.Encode())
+		if err != nil {
+			return &RetriableError{
+				Err:        fmt.Errorf("error calling archive.org cdx api: %w", err),
+				RetryAfter: 1 * time.Second,
+			}
+		}
+		if resp.StatusCode == 429 {
+			resp.Body.Close()
+			return &RetriableError{
+				Err:        fmt.Errorf("%w by archive.org cdx api", ErrRateLimited),
+				RetryAfter: retryAfter(resp, 0),
+			}
+		}
+		if resp.StatusCode != http.StatusOK {
+			defer resp.Body.Close()
+			b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, int64(unexpectedBodyLimit)+1))
+			return unexpectedResponse(resp, b, fmt.Errorf("archive.org cdx api had unexpected http status code: %v", resp.StatusCode))
+		}
+		body = resp.Body
+		return nil
+	})
+	return body, err
+}
+
+// hostStatsFromCDX tallies "urlkey timestamp" CDX lines. The CDX server
+// sorts by urlkey, so distinct URLs can be counted without remembering them.
+func hostStatsFromCDX(r io.Reader) (s HostStats, err error) {
+	scanner := bufio.NewScanner(r)
+	lastKey := ""
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		ts, err := time.Parse(timestampLayout, fields[1])
+		if err != nil {
+			return s, fmt.Errorf("error parsing cdx timestamp %v: %w", fields[1], err)
+		}
+
+		s.Captures++
+		if fields[0] != lastKey {
+			s.URLs++
+			lastKey = fields[0]
+		}
+		if s.FirstCapture.IsZero() || ts.Before(s.FirstCapture) {
+			s.FirstCapture = ts
+		}
+		if ts.After(s.LastCapture) {
+			s.LastCapture = ts
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return s, fmt.Errorf("error reading cdx response: %w", err)
+	}
+	return s, nil
+}
+
+type CaptureChange struct {
+	Timestamp  time.Time
+	StatusCode string
+	// Digest of the captured content.
+	Digest string
+	// Whether the content differs from the previous capture.
+	// Always true for the first capture.
+	Changed bool
+	// How similar the capture's text is to the last changed capture's,
+	// from 0 to 1. Only set with WithChangeThreshold.
+	Similarity float64
+}
+
+// Gets every capture of a URL, oldest first, marking which captures
+// have different content from the capture before them. Useful for only
+// reviewing snapshots where something actually changed.
+//
+// By default captures are compared by the digest the Wayback Machine
+// keeps of their content, which is cheap but counts any change at all,
+// such as a new timestamp or ad, as a change. WithChangeThreshold compares
+// the text of captures whose digests differ instead, so only meaningful
+// changes count; the digest check is still used for captures that
+// can't be downloaded.
+// Does not need to be authenticated.
+func GetChangeCalendar(pageURL string, opts ...CallOption) (changes []CaptureChange, err error) {
+	return defaultClient.GetChangeCalendar(pageURL, opts...)
+}
+
+// GetChangeCalendar is the Client version of GetChangeCalendar.
+func (c *Client) GetChangeCalendar(pageURL string, opts ...CallOption) (changes []CaptureChange, err error) {
+	o := c.callOptions(opts)
+	ctx, cancel := o.context()
+	defer cancel()
+	body, err := c.queryCDX(ctx, o.retries, url.Values{
+		"url": {pageURL},
+		"fl":  {"timestamp,statuscode,digest"},
+	})
+	if err != nil {
+		return changes, err
+	}
+	defer body.Close()
+
+	changes, err = changesFromCDX(body)
+	if err != nil || o.changeThreshold <= 0 {
+		return changes, err
+	}
+	return changes, c.compareChanges(ctx, pageURL, changes, o.changeThreshold)
+}
+
+// compareChanges unmarks changed captures whose text is at least threshold
+// similar to the last changed capture's.
+func (c *Client) compareChanges(ctx context.Context, pageURL string, changes []CaptureChange, threshold float64) error {
+	var last uint64
+	haveLast := false
+	for i := range changes {
+		if !changes[i].Changed {
+			continue
+		}
+		content, _, err := c.downloadSnapshot(ctx, changes[i].Timestamp.Format(timestampLayout), pageURL)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			// Keep the digest comparison
+			continue
+		}
+
+		hash := simhash(content)
+		if haveLast {
+			changes[i].Similarity = similarity(hash, last)
+			if changes[i].Similarity >= threshold {
+				changes[i].Changed = false
+				continue
+			}
+		}
+		last, haveLast = hash, true
+	}
+	return nil
+}
+
+// simhash returns a 64-bit simhash of the words in a page's text, which
+// differs in few bits between pages with similar text.
+func simhash(content []byte) uint64 {
+	words := strings.Fields(strings.ToLower(tagRegex.ReplaceAllString(string(content), " ")))
+	var weights [64]int
+	// Hash runs of three words so word order counts
+	for i := 0; i == 0 || i+3 <= len(words); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:min(i+3, len(words))], " ")))
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var hash uint64
+	for bit, w := range weights {
+		if w > 0 {
+			hash |= 1 << bit
+		}
+	}
+	return hash
+}
+
+// similarity returns the fraction of bits two simhashes share.
+func similarity(a, b uint64) float64 {
+	return 1 - float64(bits.OnesCount64(a^b))/64
+}
+
+// changesFromCDX reads "timestamp statuscode digest" CDX lines.
+func changesFromCDX(r io.Reader) (changes []CaptureChange, err error) {
+	scanner := bufio.NewScanner(r)
+	lastDigest := ""
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 {
+			continue
+		}
+		ts, err := time.Parse(timestampLayout, fields[0])
+		if err != nil {
+			return changes, fmt.Errorf("error parsing cdx timestamp %v: %w", fields[0], err)
+		}
+
+		changes = append(changes, CaptureChange{
+			Timestamp:  ts,
+			StatusCode: fields[1],
+			Digest:     fields[2],
+			Changed:    len(changes) == 0 || fields[2] != lastDigest,
+		})
+		lastDigest = fields[2]
+	}
+	if err := scanner.Err(); err != nil {
+		return changes, fmt.Errorf("error reading cdx response: %w", err)
+	}
+	return changes, nil
+}
+
+type Snapshot struct {
+	Timestamp   time.Time
+	OriginalURL string
+	MimeType    string
+	StatusCode  string
+	Digest      string
+	// Wayback Machine link to the snapshot.
+	URL string
+}
+
+// Yields every snapshot of a URL in the Wayback Machine, oldest first.
+// Snapshots are read from archive.org as the caller ranges over them,
+// so even URLs with huge numbers of captures are cheap to iterate, and
+// breaking out of the loop stops the request. If there's an error, it's
+// yielded once and iteration stops.
+// Does not need to be authenticated.
+func Snapshots(pageURL string, opts ...CallOption) iter.Seq2[Snapshot, error] {
+	return defaultClient.Snapshots(pageURL, opts...)
+}
+
+// Snapshots is the Client version of Snapshots.
+func (c *Client) Snapshots(pageURL string, opts ...CallOption) iter.Seq2[Snapshot, error] {
+	return func(yield func(Snapshot, error) bool) {
+		o := c.callOptions(opts)
+		ctx, cancel := o.context()
+		defer cancel()
+		body, err := c.queryCDX(ctx, o.retries, url.Values{
+			"url": {pageURL},
+			"fl":  {"timestamp,original,mimetype,statuscode,digest"},
+		})
+		if err != nil {
+			yield(Snapshot{}, err)
+			return
+		}
+		defer body.Close()
+
+		scanner := bufio.NewScanner(body)
+		for scanner.Scan() {
+			s, err := snapshotFromCDX(scanner.Text())
+			if err != nil {
+				yield(Snapshot{}, err)
+				return
+			}
+			if !yield(s, nil) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield(Snapshot{}, fmt.Errorf("error reading cdx response: %w", err))
+		}
+	}
+}
+
+// snapshotFromCDX parses a "timestamp original mimetype statuscode digest"
+// CDX line.
+func snapshotFromCDX(line string) (s Snapshot, err error) {
+	fields := strings.Fields(line)
+	if len(fields) < 5 {
+		return s, fmt.Errorf("unexpected cdx line: %v", line)
+	}
+	ts, err := time.Parse(timestampLayout, fields[0])
+	if err != nil {
+		return s, fmt.Errorf("error parsing cdx timestamp %v: %w", fields[0], err)
+	}
+	return Snapshot{
+		Timestamp:   ts,
+		OriginalURL: fields[1],
+		MimeType:    fields[2],
+		StatusCode:  fields[3],
+		Digest:      fields[4],
+		URL:         BuildSnapshotURL(fields[0], fields[1], ""),
+	}, nil
+}
@@ -0,0 +1,154 @@
+package archiveorg
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHostStatsFromCDX(t *testing.T) {
+	cdx := `com,example)/ 20020120142510
+com,example)/ 20230101000000
+com,example)/about 20100505050505
+com,example)/contact 19990101000000
+`
+	s, err := hostStatsFromCDX(strings.NewReader(cdx))
+	if err != nil {
+		t.Fatalf("error reading cdx: %v", err)
+	}
+	if s.URLs != 3 || s.Captures != 4 {
+		t.Errorf("got %v urls and %v captures, want 3 and 4", s.URLs, s.Captures)
+	}
+	if want := time.Date(1999, 1, 1, 0, 0, 0, 0, time.UTC); !s.FirstCapture.Equal(want) {
+		t.Errorf("first capture %v, want %v", s.FirstCapture, want)
+	}
+	if want := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC); !s.LastCapture.Equal(want) {
+		t.Errorf("last capture %v, want %v", s.LastCapture, want)
+	}
+}
+
+func TestQueryCDXRetries(t *testing.T) {
+	var statuses []int
+	c := stubClient(func(r *http.Request) (*http.Response, error) {
+		status := statuses[0]
+		statuses = statuses[1:]
+		resp, _ := stubResponse(r, status, "com,example)/ 20200101000000\n")
+		resp.Header.Set("Retry-After", "0")
+		return resp, nil
+	})
+	c.RetryPolicies = RetryPolicies{
+		ErrorClassRateLimited: {Attempts: 3},
+		ErrorClassServer:      {Attempts: 3},
+	}
+
+	statuses = []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusOK}
+	s, err := c.GetHostStats("example.com")
+	if err != nil || s.Captures != 1 {
+		t.Errorf("got %+v and error %v after retrying, want 1 capture", s, err)
+	}
+
+	statuses = []int{http.StatusBadGateway, http.StatusOK}
+	if _, err := c.GetHostStats("example.com", WithRetries(1)); ClassifyError(err) != ErrorClassServer {
+		t.Errorf("got error %v with retries disabled, want a server error", err)
+	}
+}
+
+func TestChangesFromCDX(t *testing.T) {
+	cdx := `20200101000000 200 AAAA
+20200201000000 200 AAAA
+20200301000000 200 BBBB
+20200401000000 301 CCCC
+20200501000000 200 BBBB
+`
+	changes, err := changesFromCDX(strings.NewReader(cdx))
+	if err != nil {
+		t.Fatalf("error reading cdx: %v", err)
+	}
+	want := []bool{true, false, true, true, true}
+	if len(changes) != len(want) {
+		t.Fatalf("got %v changes, want %v", len(changes), len(want))
+	}
+	for i, c := range changes {
+		if c.Changed != want[i] {
+			t.Errorf("capture %v at %v: changed %v, want %v", i, c.Timestamp, c.Changed, want[i])
+		}
+	}
+}
+
+func TestSnapshots(t *testing.T) {
+	cdx := `20200101000000 https://example.com/ text/html 200 AAAA
+20200201000000 https://example.com/ text/html 200 BBBB
+20200301000000 https://example.com/ text/html 200 CCCC
+`
+	c := stubClient(func(r *http.Request) (*http.Response, error) {
+		return stubResponse(r, http.StatusOK, cdx)
+	})
+
+	var got []Snapshot
+	for s, err := range c.Snapshots("https://example.com/") {
+		if err != nil {
+			t.Fatalf("error iterating snapshots: %v", err)
+		}
+		got = append(got, s)
+		if len(got) == 2 {
+			break
+		}
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %v snapshots, want 2", len(got))
+	}
+	if want := "https://web.archive.org/web/20200201000000/https://example.com/"; got[1].URL != want {
+		t.Errorf("snapshot url %v, want %v", got[1].URL, want)
+	}
+}
+
+func TestSimhashSimilarity(t *testing.T) {
+	page := "<html><body><p>The quick brown fox jumps over the lazy dog while the farmer watches from the porch of the old red barn at the edge of the field, drinking coffee and reading the morning paper about the weather.</p></body></html>"
+	edited := strings.Replace(page, "morning", "evening", 1)
+	other := "<html><body><h1>Quarterly report</h1><p>Revenue grew in every region this year, led by strong demand for new products and services across our retail and online channels.</p></body></html>"
+
+	if s := similarity(simhash([]byte(page)), simhash([]byte(page))); s != 1 {
+		t.Errorf("identical pages have similarity %v, want 1", s)
+	}
+	small := similarity(simhash([]byte(page)), simhash([]byte(edited)))
+	large := similarity(simhash([]byte(page)), simhash([]byte(other)))
+	if small < 0.8 || large >= 0.8 {
+		t.Errorf("small edit has similarity %v and different page %v, want >= 0.8 and < 0.8", small, large)
+	}
+}
+
+func TestGetChangeCalendarThreshold(t *testing.T) {
+	page := "<p>The quick brown fox jumps over the lazy dog while the farmer watches from the porch of the old red barn at the edge of the field, drinking coffee and reading the morning paper about the weather.</p>"
+	pages := map[string]string{
+		"20200101000000": page,
+		"20200201000000": strings.Replace(page, "morning", "evening", 1),
+		"20200301000000": "<h1>Quarterly report</h1><p>Revenue grew in every region this year, led by strong demand for new products and services across our retail and online channels.</p>",
+	}
+	c := stubClient(func(r *http.Request) (*http.Response, error) {
+		if strings.Contains(r.URL.Path, "/cdx/") {
+			return stubResponse(r, http.StatusOK, "20200101000000 200 AAAA\n20200201000000 200 BBBB\n20200301000000 200 CCCC\n20200401000000 200 DDDD\n")
+		}
+		for ts, body := range pages {
+			if strings.Contains(r.URL.Path, ts) {
+				return stubResponse(r, http.StatusOK, body)
+			}
+		}
+		return stubResponse(r, http.StatusNotFound, "")
+	})
+
+	changes, err := c.GetChangeCalendar("https://example.com", WithChangeThreshold(0.8))
+	if err != nil {
+		t.Fatalf("error getting change calendar: %v", err)
+	}
+	// The last capture can't be downloaded, so its digest is used
+	want := []bool{true, false, true, true}
+	if len(changes) != len(want) {
+		t.Fatalf("got %v changes, want %v", len(changes), len(want))
+	}
+	for i, c := range changes {
+		if c.Changed != want[i] {
+			t.Errorf("capture %v at %v: changed %v (similarity %v), want %v", i, c.Timestamp, c.Changed, c.Similarity, want[i])
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package archiveorg
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+const modulePath string = "github.com/tyzbit/go-archive"
+
+// DefaultUserAgent identifies this package and where to find it, as
+// archive.org asks automated clients to do. Set Client.UserAgent to
+// something that includes your own contact info where you can.
+func DefaultUserAgent() string {
+	return defaultUserAgent()
+}
+
+// defaultUserAgent builds the default user agent the first time it's
+// needed, since reading the build info on every request is wasteful.
+var defaultUserAgent = sync.OnceValue(func() string {
+	return "go-archive/" + moduleVersion() + " (+https://" + modulePath + ")"
+})
+
+// The most idle connections kept open to each host. net/http's default
+// of 2 means busy shared clients keep reconnecting to archive.org.
+const maxIdleConnsPerHost int = 16
+
+// defaultTransport is shared by every Client without an HTTPClient, so
+// they all draw from one connection pool. It's never modified after
+// it's made.
+var defaultTransport http.RoundTripper = newTransport()
+
+func newTransport() http.RoundTripper {
+	t, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return http.DefaultTransport
+	}
+	t = t.Clone()
+	t.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	return t
+}
+
+// moduleVersion returns the version of this package the running
+// program was built with, if it was built as a dependency.
+func moduleVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if dep.Path == modulePath {
+				return dep.Version
+			}
+		}
+	}
+	return "devel"
+}
+
+// Middleware wraps the transport a Client sends requests with. It can
+// change requests before they're sent, inspect or replace responses,
+// or answer requests itself, for example to inject auth, log, cache or
+// replay recorded fixtures. Requests it passes on to next must keep the
+// context of the request it was given, such as by using r.Clone.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc lets an ordinary function be used as an http.RoundTripper,
+// which is handy for writing Middleware.
+type RoundTripperFunc func(r *http.Request) (*http.Response, error)
+
+// RoundTrip calls f(r).
+func (f RoundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// Client makes requests to archive.org and the other archives this package
+// supports. The package-level functions use a default Client; make your own
+// to customize how requests are sent.
+//
+// A Client is safe for concurrent use by multiple goroutines, and should be
+// shared rather than made per request so connections are reused. Set its
+// fields before sharing it; Use can be called at any time.
+type Client struct {
+	// The HTTP client requests are made with. If nil, a client with
+	// a transport shared by all Clients is used.
+	HTTPClient *http.Client
+	// Sent with every request. DefaultUserAgent is used if empty.
+	UserAgent string
+	// How to retry each class of error. Classes missing from the map use
+	// their policy from DefaultRetryPolicies.
+	RetryPolicies RetryPolicies
+
+	// Defaults for every call, which CallOptions can override.
+	// RetryAttempts defaults to 3 if 0; see WithRetries, WithCookie,
+	// WithPermaAPIKey and WithTimeout.
+	RetryAttempts uint
+	Cookie        string
+	PermaAPIKey   string
+	Timeout       time.Duration
+
+	mu    sync.Mutex
+	chain *middlewareChain
+}
+
+// middlewareChain is the transports made from a client's middleware,
+// outermost first. Each Middleware is only called once, when it's added,
+// so the transport it returns can keep state between requests.
+type middlewareChain struct {
+	mu     sync.RWMutex
+	layers []http.RoundTripper
+}
+
+// baseTransportKey is the request context key of the transport
+// below all middleware.
+type baseTransportKey struct{}
+
+// chainLink is the next transport passed to the middleware at position i-1.
+// It looks the transport up when a request is sent, so middleware added
+// later still sees requests.
+type chainLink struct {
+	chain *middlewareChain
+	i     int
+}
+
+func (l chainLink) RoundTrip(r *http.Request) (*http.Response, error) {
+	return l.chain.roundTrip(l.i, r)
+}
+
+func (m *middlewareChain) add(middleware ...Middleware) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, mw := range middleware {
+		m.layers = append(m.layers, mw(chainLink{chain: m, i: len(m.layers) + 1}))
+	}
+}
+
+// roundTrip sends r through the layers from position i on.
+func (m *middlewareChain) roundTrip(i int, r *http.Request) (*http.Response, error) {
+	m.mu.RLock()
+	var next http.RoundTripper
+	if i < len(m.layers) {
+		next = m.layers[i]
+	}
+	m.mu.RUnlock()
+	if next == nil {
+		next, _ = r.Context().Value(baseTransportKey{}).(http.RoundTripper)
+	}
+	if next == nil {
+		// Guessing would skip the transport the client was configured with,
+		// such as a proxy
+		return nil, fmt.Errorf("middleware sent a request to %v without the context of the request it was given", r.URL)
+	}
+	return next.RoundTrip(r)
+}
+
+var defaultClient = NewClient()
+
+// NewClient returns a Client with default settings.
+func NewClient() *Client {
+	return &Client{}
+}
+
+func clientOrDefault(c *Client) *Client {
+	if c == nil {
+		return defaultClient
+	}
+	return c
+}
+
+// Use adds middleware to the client. Middleware added first is outermost,
+// so it sees requests first and responses last.
+// Each Middleware is called once, here, so the transport it returns can
+// keep state such as a cache between requests.
+func (c *Client) Use(middleware ...Middleware) {
+	c.middlewareChain().add(middleware...)
+}
+
+func (c *Client) middlewareChain() *middlewareChain {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.chain == nil {
+		c.chain = &middlewareChain{}
+	}
+	return c.chain
+}
+
+// WithUserAgent returns a copy of the client that identifies itself
+// with userAgent, for overriding the user agent of individual calls:
+//
+//	c.WithUserAgent("my-bot/1.0 (admin@example.com)").ArchiveURL(...)
+//
+// The copy shares the client's middleware.
+func (c *Client) WithUserAgent(userAgent string) *Client {
+	return &Client{
+		HTTPClient:    c.HTTPClient,
+		UserAgent:     userAgent,
+		RetryPolicies: c.RetryPolicies,
+		RetryAttempts: c.RetryAttempts,
+		Cookie:        c.Cookie,
+		PermaAPIKey:   c.PermaAPIKey,
+		Timeout:       c.Timeout,
+		chain:         c.middlewareChain(),
+	}
+}
+
+func (c *Client) userAgent() string {
+	if c.UserAgent != "" {
+		return c.UserAgent
+	}
+	return DefaultUserAgent()
+}
+
+// httpClient returns an http.Client that sends requests through
+// the client's middleware.
+func (c *Client) httpClient() *http.Client {
+	client := http.Client{}
+	if c.HTTPClient != nil {
+		client = *c.HTTPClient
+	}
+
+	base := client.Transport
+	if base == nil {
+		base = defaultTransport
+	}
+	chain := c.middlewareChain()
+	client.Transport = userAgentTransport(RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		r = r.WithContext(context.WithValue(r.Context(), baseTransportKey{}, base))
+		return chain.roundTrip(0, r)
+	}), c.userAgent())
+	return &client
+}
+
+// userAgentTransport sets the User-Agent of requests that don't already have
+// one, before any middleware sees them.
+func userAgentTransport(next http.RoundTripper, userAgent string) http.RoundTripper {
+	return RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if r.Header.Get("User-Agent") != "" {
+			return next.RoundTrip(r)
+		}
+		r = r.Clone(r.Context())
+		r.Header.Set("User-Agent", userAgent)
+		return next.RoundTrip(r)
+	})
+}
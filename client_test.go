@@ -0,0 +1,154 @@
+package archiveorg
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// stub is middleware that answers every request with respond instead of
+// sending it, so tests never reach the network.
+func stub(respond RoundTripperFunc) Middleware {
+	return func(http.RoundTripper) http.RoundTripper {
+		return respond
+	}
+}
+
+// stubClient returns a Client that answers every request with respond.
+func stubClient(respond RoundTripperFunc) *Client {
+	c := NewClient()
+	c.Use(stub(respond))
+	return c
+}
+
+// stubResponse answers r with status and body.
+func stubResponse(r *http.Request, status int, body string) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+		Request:    r,
+	}, nil
+}
+
+func TestClientMiddleware(t *testing.T) {
+	var order []string
+	tag := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next.RoundTrip(r)
+			})
+		}
+	}
+	c := NewClient()
+	c.Use(tag("first"), tag("second"))
+	c.Use(stub(func(r *http.Request) (*http.Response, error) {
+		return stubResponse(r, http.StatusOK, `{"first_ts":"19970101000000","last_ts":"20230101000000"}`)
+	}))
+
+	r, err := c.CheckArchiveSparkline("https://example.com")
+	if err != nil {
+		t.Fatalf("error checking sparkline: %v", err)
+	}
+	if r.FirstTs != "19970101000000" {
+		t.Errorf("unexpected sparkline response: %+v", r)
+	}
+	if strings.Join(order, ",") != "first,second" {
+		t.Errorf("middleware ran in order %v, want first,second", order)
+	}
+}
+
+func TestClientUserAgent(t *testing.T) {
+	var got []string
+	c := stubClient(func(r *http.Request) (*http.Response, error) {
+		got = append(got, r.Header.Get("User-Agent"))
+		return stubResponse(r, http.StatusOK, `{}`)
+	})
+
+	c.CheckArchiveSparkline("https://example.com")
+	c.WithUserAgent("test-bot/1.0 (test@example.com)").CheckArchiveSparkline("https://example.com")
+	c.UserAgent = "configured-bot/1.0"
+	c.CheckArchiveSparkline("https://example.com")
+
+	want := []string{DefaultUserAgent(), "test-bot/1.0 (test@example.com)", "configured-bot/1.0"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("sent user agents %q, want %q", got, want)
+	}
+	if !strings.HasPrefix(DefaultUserAgent(), "go-archive/") {
+		t.Errorf("unexpected default user agent: %v", DefaultUserAgent())
+	}
+}
+
+func TestClientConcurrentUse(t *testing.T) {
+	c := stubClient(func(r *http.Request) (*http.Response, error) {
+		return stubResponse(r, http.StatusOK, `{}`)
+	})
+	passthrough := func(next http.RoundTripper) http.RoundTripper { return next }
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := c.CheckArchiveSparkline("https://example.com"); err != nil {
+				t.Errorf("error checking sparkline: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			c.Use(passthrough)
+			c.WithUserAgent("test-bot/1.0").CheckArchiveSparkline("https://example.com")
+		}()
+	}
+	wg.Wait()
+}
+
+func TestClientMiddlewareKeepsState(t *testing.T) {
+	built := 0
+	var sent []int
+	c := NewClient()
+	c.Use(func(next http.RoundTripper) http.RoundTripper {
+		built++
+		requests := 0
+		return RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			requests++
+			sent = append(sent, requests)
+			return next.RoundTrip(r)
+		})
+	})
+	c.Use(stub(func(r *http.Request) (*http.Response, error) {
+		return stubResponse(r, http.StatusOK, `{}`)
+	}))
+
+	c.CheckArchiveSparkline("https://example.com")
+	c.WithUserAgent("test-bot/1.0").CheckArchiveSparkline("https://example.com")
+	if built != 1 {
+		t.Errorf("middleware was built %v times, want 1", built)
+	}
+	if len(sent) != 2 || sent[1] != 2 {
+		t.Errorf("middleware counted requests %v, want [1 2]", sent)
+	}
+}
+
+func TestClientMiddlewareNewContext(t *testing.T) {
+	c := NewClient()
+	c.HTTPClient = &http.Client{Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return stubResponse(r, http.StatusOK, `{}`)
+	})}
+	c.Use(func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			req, err := http.NewRequest(r.Method, r.URL.String(), nil)
+			if err != nil {
+				return nil, err
+			}
+			return next.RoundTrip(req)
+		})
+	})
+
+	if _, err := c.CheckArchiveSparkline("https://example.com"); err == nil || !strings.Contains(err.Error(), "context") {
+		t.Errorf("unexpected error for request without the client's context: %v", err)
+	}
+}
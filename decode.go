@@ -0,0 +1,179 @@
+package archiveorg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// archive.org changes the shape of its responses from time to time, such
+// as sending a timestamp as a number instead of a string or an empty array
+// instead of an empty object. Response types decode themselves with these
+// helpers, which convert what they're given to the field's type where they
+// can and use the zero value where they can't, so one odd field doesn't
+// fail a whole response.
+
+// jsonObject is a JSON object whose fields haven't been decoded yet.
+type jsonObject map[string]json.RawMessage
+
+// decodeObject decodes a response body. The body itself must be an object,
+// otherwise it's not a response we know anything about. A nil object
+// means the body was null.
+func decodeObject(data []byte) (o jsonObject, err error) {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		return nil, nil
+	}
+	if len(data) == 0 || data[0] != '{' {
+		return nil, fmt.Errorf("expected a json object, got %.20q", data)
+	}
+	if err := json.Unmarshal(data, &o); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func (o jsonObject) string(key string) string {
+	return rawString(o[key])
+}
+
+func (o jsonObject) int(key string) int {
+	return rawInt(o[key])
+}
+
+func (o jsonObject) float32(key string) float32 {
+	f := rawFloat(o[key])
+	if math.Abs(f) > math.MaxFloat32 {
+		return 0
+	}
+	return float32(f)
+}
+
+func (o jsonObject) bool(key string) bool {
+	return rawBool(o[key])
+}
+
+func (o jsonObject) strings(key string) []string {
+	return rawStrings(o[key])
+}
+
+// object returns a nested object, or an empty one if the field isn't one.
+func (o jsonObject) object(key string) jsonObject {
+	return rawObject(o[key])
+}
+
+// rawString decodes strings, and numbers and booleans as they're written.
+func rawString(raw json.RawMessage) string {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return ""
+	}
+	switch raw[0] {
+	case '"':
+		var s string
+		json.Unmarshal(raw, &s)
+		return s
+	case 'n', '{', '[':
+		return ""
+	default:
+		return string(raw)
+	}
+}
+
+// rawFloat decodes numbers, numeric strings and booleans. NaN and
+// infinities can't be encoded as JSON, so they're treated as 0.
+func rawFloat(raw json.RawMessage) float64 {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return 0
+	}
+	switch raw[0] {
+	case 't':
+		return 1
+	case 'f', 'n', '{', '[':
+		return 0
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(rawString(raw)), 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0
+	}
+	return f
+}
+
+// rawInt decodes the same values as rawFloat, dropping any fraction.
+func rawInt(raw json.RawMessage) int {
+	if i, err := strconv.ParseInt(strings.TrimSpace(rawString(raw)), 10, 0); err == nil {
+		return int(i)
+	}
+	f := rawFloat(raw)
+	if f >= math.MaxInt || f <= math.MinInt {
+		return 0
+	}
+	return int(f)
+}
+
+// rawBool decodes booleans, strings such as "true" or "1" and numbers.
+func rawBool(raw json.RawMessage) bool {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return false
+	}
+	switch raw[0] {
+	case 't':
+		return true
+	case '"':
+		b, _ := strconv.ParseBool(strings.TrimSpace(rawString(raw)))
+		return b
+	}
+	return rawFloat(raw) != 0
+}
+
+// rawStrings decodes arrays with rawString. A lone string becomes
+// an array of one.
+func rawStrings(raw json.RawMessage) []string {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil
+	}
+	switch raw[0] {
+	case '"':
+		return []string{rawString(raw)}
+	case '[':
+		var items []json.RawMessage
+		json.Unmarshal(raw, &items)
+		s := make([]string, len(items))
+		for i, item := range items {
+			s[i] = rawString(item)
+		}
+		return s
+	}
+	return nil
+}
+
+// rawInts decodes arrays with rawInt.
+func rawInts(raw json.RawMessage) []int {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || raw[0] != '[' {
+		return nil
+	}
+	var items []json.RawMessage
+	json.Unmarshal(raw, &items)
+	ints := make([]int, len(items))
+	for i, item := range items {
+		ints[i] = rawInt(item)
+	}
+	return ints
+}
+
+func rawObject(raw json.RawMessage) jsonObject {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || raw[0] != '{' {
+		return nil
+	}
+	var o jsonObject
+	json.Unmarshal(raw, &o)
+	return o
+}
@@ -0,0 +1,120 @@
+package archiveorg
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestLenientDecoding(t *testing.T) {
+	var available ArchiveOrgWaybackAvailableResponse
+	if err := json.Unmarshal([]byte(`{"url":"example.com","archived_snapshots":[]}`), &available); err != nil {
+		t.Errorf("error decoding empty snapshots: %v", err)
+	}
+	if err := json.Unmarshal([]byte(`{"archived_snapshots":{"closest":{"status":200,"available":"true","url":"http://web.archive.org/web/20200101000000/example.com","timestamp":20200101000000}}}`), &available); err != nil {
+		t.Errorf("error decoding numeric fields: %v", err)
+	}
+	closest := available.ArchivedSnapshots.Closest
+	if closest.Status != "200" || !closest.Available || closest.Timestamp != "20200101000000" {
+		t.Errorf("unexpected closest snapshot: %+v", closest)
+	}
+
+	var status ArchiveOrgWaybackStatusResponse
+	if err := json.Unmarshal([]byte(`{"status":"success","http_status":"200","duration_sec":"1.5","first_archive":1,"outlinks":{"a":"b"},"resources":"https://example.com/app.js","counters":{"embeds":"3","outlinks":2.0}}`), &status); err != nil {
+		t.Errorf("error decoding status: %v", err)
+	}
+	if status.HttpStatus != 200 || status.DurationSec != 1.5 || !status.FirstArchive || status.Outlinks != nil ||
+		len(status.Resources) != 1 || status.Counters.Embeds != 3 || status.Counters.Outlinks != 2 {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	var mementos MementoTimeTravelResponse
+	if err := json.Unmarshal([]byte(`{"mementos":{"last":{"datetime":"2020","uri":"https://archive.example/1"},"closest":"none"}}`), &mementos); err != nil {
+		t.Errorf("error decoding mementos: %v", err)
+	}
+	if mementos.BestURL() != "https://archive.example/1" || mementos.Mementos.Closest != nil {
+		t.Errorf("unexpected mementos: %+v", mementos.Mementos)
+	}
+
+	if err := json.Unmarshal([]byte(`["not","an","object"]`), &status); err == nil {
+		t.Errorf("decoded an array as a status response")
+	}
+}
+
+// fuzzDecode checks that decoding data into a new T never panics, and that
+// decoding is stable: re-encoding what was decoded and decoding it again
+// gives the same value.
+func fuzzDecode[T any](f *testing.F, seeds ...string) {
+	for _, seed := range seeds {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var first T
+		if err := json.Unmarshal(data, &first); err != nil {
+			return
+		}
+		encoded, err := json.Marshal(first)
+		if err != nil {
+			t.Fatalf("error encoding %+v: %v", first, err)
+		}
+		var second T
+		if err := json.Unmarshal(encoded, &second); err != nil {
+			t.Fatalf("error decoding %s: %v", encoded, err)
+		}
+		if !reflect.DeepEqual(first, second) {
+			t.Errorf("decoding isn't stable: %+v became %+v", first, second)
+		}
+	})
+}
+
+func FuzzAvailableResponse(f *testing.F) {
+	fuzzDecode[ArchiveOrgWaybackAvailableResponse](f,
+		`{"url":"example.com","archived_snapshots":{"closest":{"status":"200","available":true,"url":"http://web.archive.org/web/20200101000000/https://example.com/","timestamp":"20200101000000"}}}`,
+		`{"url":"example.com","archived_snapshots":[]}`,
+		`{"archived_snapshots":{"closest":{"status":200,"available":"1","timestamp":20200101000000}}}`,
+	)
+}
+
+func FuzzSaveResponse(f *testing.F) {
+	fuzzDecode[ArchiveOrgWaybackSaveResponse](f,
+		`{"url":"https://example.com","job_id":"spn2-abc"}`,
+		`{"status":"error","status_ext":"error:blocked-url","message":"blocked"}`,
+	)
+}
+
+func FuzzStatusResponse(f *testing.F) {
+	fuzzDecode[ArchiveOrgWaybackStatusResponse](f,
+		`{"status":"success","job_id":"spn2-abc","timestamp":"20200101000000","duration_sec":6.2,"http_status":200,"outlinks":["https://example.org"],"counters":{"embeds":1,"outlinks":1}}`,
+		`{"status":"pending","duration_sec":"1e40","http_status":"n/a","timestamp":20200101000000}`,
+		`{"status":"pending","duration_sec":"NaN","http_status":"Inf"}`,
+	)
+}
+
+func FuzzSparklineResponse(f *testing.F) {
+	fuzzDecode[ArchiveOrgWaybackSparklineResponse](f,
+		`{"years":{"2020":[0,1,2,3,4,5,6,7,8,9,10,11]},"first_ts":"20200101000000","last_ts":"20201231000000","status":{"2020":"422222222222"}}`,
+		`{"years":{"2020":null},"first_ts":20200101000000,"status":{"2020":4}}`,
+	)
+}
+
+func FuzzMementoResponse(f *testing.F) {
+	fuzzDecode[MementoTimeTravelResponse](f,
+		`{"original_uri":"https://example.com","mementos":{"last":{"datetime":"2020-01-01T00:00:00Z","uri":["https://archive.example/1"]}},"timemap_uri":{"json_format":"https://timemap.example"}}`,
+		`{"mementos":{"closest":{"uri":"https://archive.example/1"},"first":{}}}`,
+	)
+}
+
+func FuzzPermaArchiveResponse(f *testing.F) {
+	fuzzDecode[PermaArchiveResponse](f,
+		`{"guid":"ABCD-1234","url":"https://example.com","creation_timestamp":"2020-01-01T00:00:00Z"}`,
+		`{"detail":{"url":["not a valid url"]}}`,
+	)
+}
+
+func FuzzPermaCaptureJobResponse(f *testing.F) {
+	fuzzDecode[PermaCaptureJobResponse](f,
+		`{"guid":"ABCD-1234","status":"in_progress","step_count":2.5,"queue_position":3}`,
+		`{"status":"pending","step_count":"2.5","queue_position":"3"}`,
+		`{"status":"pending","step_count":"NaN","queue_position":"-infinity"}`,
+	)
+}
@@ -0,0 +1,166 @@
+package archiveorg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Methods that are safe to send again if a request fails.
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+}
+
+// APIRequest describes a call to an archive.org endpoint for Do.
+type APIRequest struct {
+	// Defaults to GET, or POST if Form is set. Requests with methods that
+	// aren't idempotent, such as POST, are only retried when rate limited,
+	// since sending them again could repeat a side effect like a capture.
+	Method string
+	// The endpoint, such as "/wayback/available", relative to the
+	// Wayback API. Full URLs are used as they are.
+	Path  string
+	Query url.Values
+	// Sent as a form-encoded body if set.
+	Form url.Values
+	// Added to the request's headers.
+	Header http.Header
+}
+
+// ResponseMeta describes the final response Do got.
+type ResponseMeta struct {
+	StatusCode int
+	Header     http.Header
+	// How many times the request was sent, counting retries.
+	Attempts int
+	// How long the final attempt took.
+	Duration time.Duration
+}
+
+// Calls an archive.org endpoint this package doesn't have a function for
+// yet and returns the JSON it responds with. The request gets the same
+// treatment as every other call: it goes through the client's middleware,
+// is retried according to the client's retry policies (see
+// APIRequest.Method) and waits out rate limits. The cookie (see WithCookie) is sent to archive.org hosts.
+// Non-JSON and error responses return an *UnexpectedResponseError.
+func Do(ctx context.Context, req APIRequest, opts ...CallOption) (body json.RawMessage, meta ResponseMeta, err error) {
+	return defaultClient.Do(ctx, req, opts...)
+}
+
+// Do is the Client version of Do.
+func (c *Client) Do(ctx context.Context, req APIRequest, opts ...CallOption) (body json.RawMessage, meta ResponseMeta, err error) {
+	o := c.callOptions(append([]CallOption{withContext(ctx)}, opts...))
+	ctx, cancel := o.context()
+	defer cancel()
+
+	endpoint, err := req.url()
+	if err != nil {
+		return nil, meta, err
+	}
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+		if req.Form != nil {
+			method = http.MethodPost
+		}
+	}
+
+	send := func() error {
+		meta = ResponseMeta{Attempts: meta.Attempts + 1}
+		var reqBody io.Reader
+		if req.Form != nil {
+			reqBody = strings.NewReader(req.Form.Encode())
+		}
+		r, err := http.NewRequestWithContext(ctx, method, endpoint.String(), reqBody)
+		if err != nil {
+			return fmt.Errorf("could not build http request: %w", err)
+		}
+		r.Header.Set("Accept", "application/json")
+		if req.Form != nil {
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		if o.cookie != "" && isArchiveOrgHost(endpoint.Hostname()) {
+			r.Header.Set("Cookie", o.cookie)
+		}
+		for k, v := range req.Header {
+			r.Header[http.CanonicalHeaderKey(k)] = v
+		}
+
+		start := time.Now()
+		resp, err := c.httpClient().Do(r)
+		meta.Duration = time.Since(start)
+		if err != nil {
+			return &RetriableError{
+				Err:        fmt.Errorf("error calling archive.org: %w", err),
+				RetryAfter: 3 * time.Second,
+			}
+		}
+		defer resp.Body.Close()
+		meta.StatusCode = resp.StatusCode
+		meta.Header = resp.Header
+		if resp.StatusCode == 429 {
+			return &RetriableError{
+				Err:        fmt.Errorf("%w by archive.org", ErrRateLimited),
+				RetryAfter: retryAfter(resp, 0),
+			}
+		}
+
+		b, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("error reading body: %w", err)
+		}
+		if resp.StatusCode >= 400 {
+			return unexpectedResponse(resp, b, nil)
+		}
+		body = nil
+		return unmarshalResponse(resp, b, &body)
+	}
+	err = c.retry(ctx, o.retries, func() error {
+		err := send()
+		if err != nil && !idempotentMethods[method] && !errors.Is(err, ErrRateLimited) {
+			return &noRetryError{Err: err}
+		}
+		return err
+	})
+	if err != nil {
+		return nil, meta, err
+	}
+	return body, meta, nil
+}
+
+// url returns the full URL the request is sent to.
+func (req APIRequest) url() (*url.URL, error) {
+	raw := req.Path
+	if !strings.HasPrefix(raw, "http://") && !strings.HasPrefix(raw, "https://") {
+		raw = archiveApi + "/" + strings.TrimPrefix(raw, "/")
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing url: %w", err)
+	}
+	if req.Query != nil {
+		q := u.Query()
+		for k, v := range req.Query {
+			q[k] = v
+		}
+		u.RawQuery = q.Encode()
+	}
+	return u, nil
+}
+
+func isArchiveOrgHost(host string) bool {
+	host = strings.ToLower(host)
+	return host == "archive.org" || strings.HasSuffix(host, ".archive.org")
+}
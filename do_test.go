@@ -0,0 +1,88 @@
+package archiveorg
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestClientDo(t *testing.T) {
+	var requests []*http.Request
+	c := stubClient(func(r *http.Request) (*http.Response, error) {
+		requests = append(requests, r)
+		switch {
+		case len(requests) == 1:
+			return stubResponse(r, http.StatusTooManyRequests, "")
+		case r.URL.Path == "/missing":
+			resp, _ := stubResponse(r, http.StatusNotFound, "<html>Not Found</html>")
+			resp.Header.Set("Content-Type", "text/html")
+			return resp, nil
+		}
+		return stubResponse(r, http.StatusOK, `{"count":3}`)
+	})
+	c.Cookie = "logged-in-user=someone"
+	c.RetryPolicies = RetryPolicies{
+		ErrorClassRateLimited: {Attempts: 3, Delay: time.Millisecond},
+	}
+
+	body, meta, err := c.Do(context.Background(), APIRequest{
+		Path:  "/save/status/user",
+		Query: url.Values{"_t": {"1"}},
+	})
+	if err != nil {
+		t.Fatalf("error calling endpoint: %v", err)
+	}
+	if string(body) != `{"count":3}` || meta.StatusCode != http.StatusOK || meta.Attempts != 2 {
+		t.Errorf("unexpected response: %s %+v", body, meta)
+	}
+	r := requests[len(requests)-1]
+	if r.URL.String() != archiveApi+"/save/status/user?_t=1" || r.Header.Get("Cookie") != c.Cookie {
+		t.Errorf("unexpected request: %v %v", r.URL, r.Header)
+	}
+
+	c.Do(context.Background(), APIRequest{Path: "https://example.com/api"})
+	if r := requests[len(requests)-1]; r.Header.Get("Cookie") != "" {
+		t.Errorf("cookie was sent to %v", r.URL.Host)
+	}
+
+	_, meta, err = c.Do(context.Background(), APIRequest{Path: "https://web.archive.org/missing"})
+	var unexpected *UnexpectedResponseError
+	if !errors.As(err, &unexpected) || meta.StatusCode != http.StatusNotFound {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestClientDoPost(t *testing.T) {
+	var statuses []int
+	c := stubClient(func(r *http.Request) (*http.Response, error) {
+		status := statuses[0]
+		statuses = statuses[1:]
+		if status == 0 {
+			return nil, errors.New("connection reset")
+		}
+		return stubResponse(r, status, `{"job_id":"spn2-abc"}`)
+	})
+	c.RetryPolicies = RetryPolicies{
+		ErrorClassRateLimited: {Attempts: 3, Delay: time.Millisecond},
+		ErrorClassNetwork:     {Attempts: 3, Delay: time.Millisecond},
+		ErrorClassServer:      {Attempts: 3, Delay: time.Millisecond},
+		ErrorClassOther:       {Attempts: 3, Delay: time.Millisecond},
+	}
+	save := APIRequest{Path: "/save/", Form: url.Values{"url": {"https://example.com"}}}
+
+	// Sending it again could start a second capture
+	for _, first := range []int{0, http.StatusBadGateway} {
+		statuses = []int{first, http.StatusOK}
+		if _, meta, err := c.Do(context.Background(), save); err == nil || meta.Attempts != 1 {
+			t.Errorf("post failing with %v was sent %v times, want once", first, meta.Attempts)
+		}
+	}
+
+	statuses = []int{http.StatusTooManyRequests, http.StatusOK}
+	if _, meta, err := c.Do(context.Background(), save); err != nil || meta.Attempts != 2 {
+		t.Errorf("rate limited post: %v after %v attempts", err, meta.Attempts)
+	}
+}
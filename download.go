@@ -0,0 +1,211 @@
+package archiveorg
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	// Tags whose src or href attribute points at something the page
+	// needs to display, rather than somewhere to navigate to.
+	resourceTagRegex  = regexp.MustCompile(`(?is)<(?:img|script|iframe|source|audio|video|embed|track|input|link)\b[^>]*>`)
+	resourceAttrRegex = regexp.MustCompile(`(?is)(\s(?:src|href)\s*=\s*)("[^"]*"|'[^']*'|[^\s>]+)`)
+	cssURLRegex       = regexp.MustCompile(`(?i)url\(\s*("[^"]*"|'[^']*'|[^)]*)\s*\)`)
+)
+
+// Downloads the snapshot of pageURL at timestamp, along with the images,
+// scripts and stylesheets it uses, into dir, rewriting links to them so the
+// page works offline. Resources referenced by stylesheets, such as fonts,
+// are downloaded too. Returns the path of the saved page. Resources that
+// can't be downloaded link to their Wayback Machine snapshots instead.
+// Does not need to be authenticated.
+func SaveSnapshotLocally(pageURL string, timestamp string, dir string, opts ...CallOption) (indexPath string, err error) {
+	return defaultClient.SaveSnapshotLocally(pageURL, timestamp, dir, opts...)
+}
+
+// SaveSnapshotLocally is the Client version of SaveSnapshotLocally.
+func (c *Client) SaveSnapshotLocally(pageURL string, timestamp string, dir string, opts ...CallOption) (indexPath string, err error) {
+	ctx, cancel := c.callOptions(opts).context()
+	defer cancel()
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return "", fmt.Errorf("error parsing url: %w", err)
+	}
+	page, _, err := c.downloadSnapshot(ctx, timestamp, pageURL)
+	if err != nil {
+		return "", err
+	}
+
+	d := snapshotDownloader{ctx: ctx, client: c, timestamp: timestamp, dir: dir, saved: map[string]string{}}
+	page = resourceTagRegex.ReplaceAllFunc(page, func(tag []byte) []byte {
+		lower := strings.ToLower(string(tag))
+		if strings.HasPrefix(lower, "<link") && !strings.Contains(lower, "stylesheet") && !strings.Contains(lower, "icon") {
+			return tag
+		}
+		return resourceAttrRegex.ReplaceAllFunc(tag, func(attr []byte) []byte {
+			m := resourceAttrRegex.FindSubmatch(attr)
+			local, ok := d.save(base, unquote(string(m[2])), dir)
+			if !ok {
+				return attr
+			}
+			return []byte(string(m[1]) + `"` + local + `"`)
+		})
+	})
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("error creating directory: %w", err)
+	}
+	indexPath = filepath.Join(dir, "index.html")
+	if err := os.WriteFile(indexPath, page, 0o644); err != nil {
+		return "", fmt.Errorf("error saving page: %w", err)
+	}
+	return indexPath, nil
+}
+
+type snapshotDownloader struct {
+	ctx       context.Context
+	client    *Client
+	timestamp string
+	dir       string
+	// Local paths of resources already saved, by URL.
+	saved map[string]string
+}
+
+// save downloads the resource ref points to, relative to base, and returns
+// its path relative to fromDir, or its Wayback Machine URL if it couldn't be
+// downloaded. ok is false if ref isn't a resource to download.
+func (d *snapshotDownloader) save(base *url.URL, ref string, fromDir string) (local string, ok bool) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(ref, "#") {
+		return "", false
+	}
+	// This also skips data:, javascript: and mailto: links
+	u, err := base.Parse(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", false
+	}
+	u.Fragment = ""
+
+	localPath, ok := d.saved[u.String()]
+	if !ok {
+		localPath, err = d.download(u)
+		if err != nil {
+			// A relative link would point at a file that doesn't exist
+			return BuildSnapshotURL(d.timestamp, u.String(), ""), true
+		}
+	}
+	rel, err := filepath.Rel(fromDir, localPath)
+	if err != nil {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// download saves a resource and returns where it was saved.
+// Stylesheets have their own resources downloaded and rewritten.
+func (d *snapshotDownloader) download(u *url.URL) (localPath string, err error) {
+	resourcePath, err := localResourcePath(u)
+	if err != nil {
+		return "", err
+	}
+	localPath = filepath.Join(d.dir, resourcePath)
+	// Never write outside the snapshot's directory
+	if rel, err := filepath.Rel(d.dir, localPath); err != nil || !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("resource path %v is outside %v", localPath, d.dir)
+	}
+
+	body, contentType, err := d.client.downloadSnapshot(d.ctx, d.timestamp, u.String())
+	if err != nil {
+		return "", err
+	}
+
+	// Remember the path before rewriting so stylesheets that
+	// reference each other don't download forever
+	d.saved[u.String()] = localPath
+	defer func() {
+		// Later references shouldn't link to a file that was never written
+		if err != nil {
+			delete(d.saved, u.String())
+		}
+	}()
+	if strings.Contains(contentType, "css") || strings.HasSuffix(u.Path, ".css") {
+		fromDir := filepath.Dir(localPath)
+		body = cssURLRegex.ReplaceAllFunc(body, func(ref []byte) []byte {
+			m := cssURLRegex.FindSubmatch(ref)
+			local, ok := d.save(u, unquote(string(m[1])), fromDir)
+			if !ok {
+				return ref
+			}
+			return []byte(`url("` + local + `")`)
+		})
+	}
+
+	if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+		return "", fmt.Errorf("error creating directory: %w", err)
+	}
+	if err := os.WriteFile(localPath, body, 0o644); err != nil {
+		return "", fmt.Errorf("error saving resource: %w", err)
+	}
+	return localPath, nil
+}
+
+// downloadSnapshot gets the original content archived for originalURL
+// closest to timestamp.
+func (c *Client) downloadSnapshot(ctx context.Context, timestamp string, originalURL string) (body []byte, contentType string, err error) {
+	resp, err := c.get(ctx, BuildSnapshotURL(timestamp, originalURL, "id_"))
+	if err != nil {
+		return nil, "", fmt.Errorf("error calling archive.org: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == 429 {
+		return nil, "", fmt.Errorf("%w by archive.org", ErrRateLimited)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("archive.org had unexpected http status code: %v", resp.StatusCode)
+	}
+
+	body, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("error reading body: %w", err)
+	}
+	return body, resp.Header.Get("Content-Type"), nil
+}
+
+// localResourcePath turns a resource URL into a relative file path
+// under a directory named after its host. Hosts that would make the path
+// leave that directory are rejected.
+func localResourcePath(u *url.URL) (string, error) {
+	host := u.Hostname()
+	if host == "" || host == "." || host == ".." || strings.ContainsAny(host, `/\`) {
+		return "", fmt.Errorf("can't save resource with host %q", host)
+	}
+
+	p := path.Clean("/" + u.Path)
+	if strings.HasSuffix(u.Path, "/") || p == "/" {
+		p = path.Join(p, "index")
+	}
+	if u.RawQuery != "" {
+		// Different queries are different resources
+		sum := sha1.Sum([]byte(u.RawQuery))
+		ext := path.Ext(p)
+		p = strings.TrimSuffix(p, ext) + "_" + hex.EncodeToString(sum[:4]) + ext
+	}
+	return filepath.Join(host, filepath.FromSlash(p)), nil
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
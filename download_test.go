@@ -0,0 +1,62 @@
+package archiveorg
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveSnapshotLocally(t *testing.T) {
+	archived := map[string]string{
+		"https://example.com/":             `<html><head><link rel="stylesheet" href="/css/site.css"><link rel="canonical" href="https://example.com/"></head><body><img src='logo.png?v=2'><a href="/about">About</a><img src="data:image/gif;base64,R0lGOD"><script src="https://cdn.example.net/app.js"></script><img src="//../escaped.png"><img src="missing.png"><img src="/a"><img src="/a/b"><img src="/a/b"></body></html>`,
+		"https://example.com/css/site.css": `body { background: url(../img/bg.png); }`,
+		"https://example.com/logo.png?v=2": "PNG",
+		"https://example.com/img/bg.png":   "PNG",
+		"https://cdn.example.net/app.js":   "alert(1)",
+		"https://../escaped.png":           "PNG",
+		// Can't be saved, since a file is already where its directory goes
+		"https://example.com/a":   "PNG",
+		"https://example.com/a/b": "PNG",
+	}
+	c := stubClient(func(r *http.Request) (*http.Response, error) {
+		s, err := ParseSnapshotURL(r.URL.String())
+		body, ok := archived[s.OriginalURL]
+		if err != nil || s.Modifier != "id_" || !ok {
+			return stubResponse(r, http.StatusNotFound, "")
+		}
+		return stubResponse(r, http.StatusOK, body)
+	})
+
+	dir := t.TempDir()
+	index, err := c.SaveSnapshotLocally("https://example.com/", "20200101000000", dir)
+	if err != nil {
+		t.Fatalf("error saving snapshot: %v", err)
+	}
+
+	page, _ := os.ReadFile(index)
+	for _, want := range []string{`href="example.com/css/site.css"`, `src="example.com/logo_`, `src="cdn.example.net/app.js"`, `href="/about"`, `href="https://example.com/"`, `data:image/gif`, `src="https://web.archive.org/web/20200101000000/https://../escaped.png"`, `src="https://web.archive.org/web/20200101000000/https://example.com/missing.png"`} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("saved page doesn't contain %v: %s", want, page)
+		}
+	}
+
+	if n := strings.Count(string(page), `src="https://web.archive.org/web/20200101000000/https://example.com/a/b"`); n != 2 {
+		t.Errorf("resource that couldn't be saved was linked to the wayback machine %v times, want 2: %s", n, page)
+	}
+
+	css, err := os.ReadFile(filepath.Join(dir, "example.com", "css", "site.css"))
+	if err != nil {
+		t.Fatalf("stylesheet wasn't saved: %v", err)
+	}
+	if !strings.Contains(string(css), `url("../img/bg.png")`) {
+		t.Errorf("stylesheet wasn't rewritten: %s", css)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "example.com", "img", "bg.png")); err != nil {
+		t.Errorf("stylesheet image wasn't saved: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "..", "escaped.png")); err == nil {
+		t.Errorf("resource was saved outside the snapshot's directory")
+	}
+}
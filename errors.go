@@ -0,0 +1,122 @@
+package archiveorg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// How much of an unexpected response body is kept for error messages.
+const unexpectedBodyLimit int = 512
+
+// ErrUnexpectedResponse is matched by errors.Is for every
+// *UnexpectedResponseError.
+var ErrUnexpectedResponse = errors.New("unexpected response")
+
+// UnexpectedResponseError is returned when an API responds with something
+// other than the JSON it should have, such as an HTML maintenance page
+// or a Cloudflare challenge. When trying again later might help, it's
+// wrapped in a *RetriableError; use errors.As to get at it either way.
+type UnexpectedResponseError struct {
+	StatusCode  int
+	ContentType string
+	// The start of the response body.
+	Body string
+	// The decoding error, if the body looked like JSON but wasn't valid.
+	Err error
+}
+
+// Error returns the status code and the start of the body.
+func (e *UnexpectedResponseError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("unexpected response (status %v, %v): %v, body: %v", e.StatusCode, e.ContentType, e.Err, e.Body)
+	}
+	return fmt.Sprintf("unexpected response (status %v, %v), body: %v", e.StatusCode, e.ContentType, e.Body)
+}
+
+func (e *UnexpectedResponseError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrUnexpectedResponse.
+func (e *UnexpectedResponseError) Is(target error) bool {
+	return target == ErrUnexpectedResponse
+}
+
+// Retriable reports whether the response looks like a temporary problem,
+// such as rate limiting, maintenance or a challenge page.
+func (e *UnexpectedResponseError) Retriable() bool {
+	if e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500 {
+		return true
+	}
+	body := strings.ToLower(e.Body)
+	for _, hint := range []string{"maintenance", "cloudflare", "challenge", "try again", "temporarily"} {
+		if strings.Contains(body, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unwrap returns the underlying error.
+func (e *RetriableError) Unwrap() error {
+	return e.Err
+}
+
+// unmarshalResponse decodes a JSON API response body into v. Responses that
+// aren't JSON become an *UnexpectedResponseError instead of leaking the whole
+// body into a json error.
+func unmarshalResponse(resp *http.Response, body []byte, v any) error {
+	contentType := resp.Header.Get("Content-Type")
+	trimmed := bytes.TrimSpace(body)
+	looksLikeJSON := len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+	if !looksLikeJSON || strings.Contains(contentType, "html") {
+		return unexpectedResponse(resp, body, nil)
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return unexpectedResponse(resp, body, fmt.Errorf("error unmarshalling json: %w", err))
+	}
+	return nil
+}
+
+// unexpectedResponse builds an *UnexpectedResponseError for resp, wrapped
+// in a *RetriableError if it looks temporary.
+func unexpectedResponse(resp *http.Response, body []byte, err error) error {
+	if len(body) > unexpectedBodyLimit {
+		body = append(body[:unexpectedBodyLimit:unexpectedBodyLimit], "..."...)
+	}
+	e := &UnexpectedResponseError{
+		StatusCode:  resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        string(body),
+		Err:         err,
+	}
+	if !e.Retriable() {
+		return e
+	}
+	return &RetriableError{
+		Err:        e,
+		RetryAfter: retryAfter(resp, 3*time.Second),
+	}
+}
+
+// retryAfter returns how long the Retry-After header asks to wait,
+// or fallback if there isn't one.
+func retryAfter(resp *http.Response, fallback time.Duration) time.Duration {
+	header := resp.Header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,57 @@
+package archiveorg
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestUnmarshalResponse(t *testing.T) {
+	cases := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		wantErr     bool
+		retriable   bool
+	}{
+		{"json", 200, "application/json", `{"url":"https://example.com"}`, false, false},
+		{"maintenance", 503, "text/html", `<html><body>` + strings.Repeat("down for maintenance ", 100) + `</body></html>`, true, true},
+		{"challenge", 403, "text/html; charset=UTF-8", `<html><title>Just a moment...</title>Cloudflare</html>`, true, true},
+		{"not found", 404, "text/html", `<html>Not Found</html>`, true, false},
+		{"bad json", 200, "application/json", `{"url":`, true, false},
+	}
+
+	for _, c := range cases {
+		resp := &http.Response{StatusCode: c.status, Header: http.Header{"Content-Type": {c.contentType}}}
+		var r ArchiveOrgWaybackAvailableResponse
+		err := unmarshalResponse(resp, []byte(c.body), &r)
+		if (err != nil) != c.wantErr {
+			t.Errorf("%v: unexpected error: %v", c.name, err)
+			continue
+		}
+		if err == nil {
+			continue
+		}
+
+		var unexpected *UnexpectedResponseError
+		if !errors.As(err, &unexpected) {
+			t.Errorf("%v: error isn't an UnexpectedResponseError: %v", c.name, err)
+			continue
+		}
+		if !errors.Is(err, ErrUnexpectedResponse) {
+			t.Errorf("%v: error doesn't match ErrUnexpectedResponse: %v", c.name, err)
+		}
+		if unexpected.StatusCode != c.status {
+			t.Errorf("%v: status %v, want %v", c.name, unexpected.StatusCode, c.status)
+		}
+		if len(unexpected.Body) > unexpectedBodyLimit+len("...") {
+			t.Errorf("%v: body wasn't truncated (%v bytes)", c.name, len(unexpected.Body))
+		}
+		var retriable *RetriableError
+		if errors.As(err, &retriable) != c.retriable {
+			t.Errorf("%v: retriable %v, want %v", c.name, !c.retriable, c.retriable)
+		}
+	}
+}
@@ -1,5 +1,3 @@
 module github.com/tyzbit/go-archive
 
-go 1.18
-
-require github.com/avast/retry-go v3.0.0+incompatible // indirect
+go 1.23
@@ -0,0 +1,120 @@
+package archiveorg
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The classes of archive.org endpoint MeasureLatency checks.
+const (
+	EndpointAvailability string = "availability"
+	EndpointSave         string = "save"
+	EndpointCDX          string = "cdx"
+	EndpointReplay       string = "replay"
+)
+
+// Cheap requests for each endpoint class.
+var healthCheckURLs = []struct {
+	endpoint string
+	method   string
+	url      string
+}{
+	{EndpointAvailability, http.MethodGet, archiveApi + "/wayback/available?url=archive.org"},
+	{EndpointSave, http.MethodGet, archiveApi + "/save/status/system"},
+	{EndpointCDX, http.MethodGet, cdxApi + "?url=archive.org&limit=1"},
+	{EndpointReplay, http.MethodHead, archiveRoot + "/"},
+}
+
+type EndpointHealth struct {
+	Endpoint   string
+	URL        string
+	Available  bool
+	StatusCode int
+	// Round trip time of the request, including reading the response.
+	Latency time.Duration
+	Err     error
+}
+
+// Checks that every class of archive.org endpoint is responding.
+// Returns nil if they all are, or an error naming the ones that aren't.
+// Does not need to be authenticated.
+func Ping(ctx context.Context) error {
+	return defaultClient.Ping(ctx)
+}
+
+// Ping is the Client version of Ping.
+func (c *Client) Ping(ctx context.Context) error {
+	var down []string
+	for _, h := range c.MeasureLatency(ctx) {
+		if h.Available {
+			continue
+		}
+		reason := fmt.Sprintf("status %v", h.StatusCode)
+		if h.Err != nil {
+			reason = h.Err.Error()
+		}
+		down = append(down, h.Endpoint+" ("+reason+")")
+	}
+	if len(down) > 0 {
+		return fmt.Errorf("archive.org endpoints unavailable: %v", strings.Join(down, ", "))
+	}
+	return nil
+}
+
+// Makes a lightweight request to each class of archive.org endpoint at
+// the same time and reports whether it's available and how long it took.
+// Useful for showing archive.org's health before starting a large run.
+// Does not need to be authenticated.
+func MeasureLatency(ctx context.Context) []EndpointHealth {
+	return defaultClient.MeasureLatency(ctx)
+}
+
+// MeasureLatency is the Client version of MeasureLatency.
+func (c *Client) MeasureLatency(ctx context.Context) []EndpointHealth {
+	results := make([]EndpointHealth, len(healthCheckURLs))
+	var wg sync.WaitGroup
+	for i, check := range healthCheckURLs {
+		wg.Add(1)
+		go func(i int, endpoint string, method string, url string) {
+			defer wg.Done()
+			results[i] = c.measureEndpoint(ctx, endpoint, method, url)
+		}(i, check.endpoint, check.method, check.url)
+	}
+	wg.Wait()
+	return results
+}
+
+func (c *Client) measureEndpoint(ctx context.Context, endpoint string, method string, url string) (h EndpointHealth) {
+	h = EndpointHealth{Endpoint: endpoint, URL: url}
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		h.Err = fmt.Errorf("could not build http request: %w", err)
+		return h
+	}
+
+	client := c.httpClient()
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		h.Latency = time.Since(start)
+		h.Err = fmt.Errorf("error calling archive.org %v api: %w", endpoint, err)
+		return h
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(ioutil.Discard, resp.Body)
+	h.Latency = time.Since(start)
+	if err != nil {
+		h.Err = fmt.Errorf("error reading body: %w", err)
+		return h
+	}
+
+	h.StatusCode = resp.StatusCode
+	h.Available = resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests
+	return h
+}
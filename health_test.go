@@ -0,0 +1,39 @@
+package archiveorg
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestPing(t *testing.T) {
+	saveStatus := http.StatusOK
+	c := stubClient(func(r *http.Request) (*http.Response, error) {
+		status := http.StatusOK
+		if strings.HasPrefix(r.URL.Path, "/save/") {
+			status = saveStatus
+		}
+		return stubResponse(r, status, `{}`)
+	})
+
+	if err := c.Ping(context.Background()); err != nil {
+		t.Errorf("unexpected error pinging: %v", err)
+	}
+
+	saveStatus = http.StatusServiceUnavailable
+	err := c.Ping(context.Background())
+	if err == nil || !strings.Contains(err.Error(), EndpointSave) {
+		t.Errorf("expected save endpoint to be reported down, got %v", err)
+	}
+
+	health := c.MeasureLatency(context.Background())
+	if len(health) != len(healthCheckURLs) {
+		t.Fatalf("got %v results, want %v", len(health), len(healthCheckURLs))
+	}
+	for _, h := range health {
+		if h.Available != (h.Endpoint != EndpointSave) {
+			t.Errorf("%v: available %v", h.Endpoint, h.Available)
+		}
+	}
+}
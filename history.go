@@ -0,0 +1,149 @@
+package archiveorg
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The shortest re-archive cadence AnalyzeCaptureHistory will suggest.
+const minSuggestedCadence = 24 * time.Hour
+
+type CaptureGap struct {
+	Start time.Time
+	End   time.Time
+	// Set if the gap runs up to now, meaning the URL is overdue.
+	Ongoing bool
+}
+
+// Duration returns how long the gap is.
+func (g CaptureGap) Duration() time.Duration {
+	return g.End.Sub(g.Start)
+}
+
+type CaptureHistory struct {
+	URL          string
+	Captures     int
+	FirstCapture time.Time
+	LastCapture  time.Time
+	// Average number of captures per calendar month, from the month of the
+	// first capture up to this month.
+	CapturesPerMonth float64
+	// Times between captures longer than the threshold given, oldest first.
+	Gaps []CaptureGap
+	// The median time between captures with different content, or 0 if the
+	// content has never been seen to change.
+	ChangeInterval time.Duration
+	// How often to archive the URL to keep up with it: about as often as
+	// its content changes, but never less often than the gap threshold.
+	SuggestedCadence time.Duration
+}
+
+// Analyzes how well a URL has been archived over time, finding gaps between
+// captures longer than gapThreshold, the average captures per month, and a
+// suggested cadence for re-archiving it. Useful for deciding which URLs
+// need attention first.
+// Does not need to be authenticated.
+func AnalyzeCaptureHistory(pageURL string, gapThreshold time.Duration, opts ...CallOption) (h CaptureHistory, err error) {
+	return defaultClient.AnalyzeCaptureHistory(pageURL, gapThreshold, opts...)
+}
+
+// AnalyzeCaptureHistory is the Client version of AnalyzeCaptureHistory.
+func (c *Client) AnalyzeCaptureHistory(pageURL string, gapThreshold time.Duration, opts ...CallOption) (h CaptureHistory, err error) {
+	o := c.callOptions(opts)
+	ctx, cancel := o.context()
+	defer cancel()
+	sparkline, err := c.CheckArchiveSparkline(pageURL, nested(ctx, opts)...)
+	if err != nil {
+		return h, fmt.Errorf("error checking sparkline: %w", err)
+	}
+
+	// One capture per day is plenty to find gaps and changes
+	body, err := c.queryCDX(ctx, o.retries, url.Values{
+		"url":      {pageURL},
+		"fl":       {"timestamp,digest"},
+		"collapse": {"timestamp:8"},
+	})
+	if err != nil {
+		return h, err
+	}
+	defer body.Close()
+
+	var captures []CaptureChange
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		ts, err := time.Parse(timestampLayout, fields[0])
+		if err != nil {
+			return h, fmt.Errorf("error parsing cdx timestamp %v: %w", fields[0], err)
+		}
+		captures = append(captures, CaptureChange{Timestamp: ts, Digest: fields[1]})
+	}
+	if err := scanner.Err(); err != nil {
+		return h, fmt.Errorf("error reading cdx response: %w", err)
+	}
+
+	h = analyzeCaptureHistory(captures, sparkline, gapThreshold, time.Now())
+	h.URL = pageURL
+	return h, nil
+}
+
+// analyzeCaptureHistory works out a CaptureHistory from captures,
+// oldest first, and the sparkline's monthly capture counts.
+func analyzeCaptureHistory(captures []CaptureChange, sparkline ArchiveOrgWaybackSparklineResponse, gapThreshold time.Duration, now time.Time) (h CaptureHistory) {
+	for _, months := range sparkline.Years {
+		for _, count := range months {
+			h.Captures += count
+		}
+	}
+	h.SuggestedCadence = gapThreshold
+	if len(captures) == 0 {
+		return h
+	}
+
+	h.FirstCapture = captures[0].Timestamp
+	h.LastCapture = captures[len(captures)-1].Timestamp
+	if h.Captures == 0 {
+		h.Captures = len(captures)
+	}
+	months := (now.Year()-h.FirstCapture.Year())*12 + int(now.Month()) - int(h.FirstCapture.Month()) + 1
+	if months < 1 {
+		months = 1
+	}
+	h.CapturesPerMonth = float64(h.Captures) / float64(months)
+
+	var changes []time.Duration
+	lastChange := captures[0]
+	for i := 1; i < len(captures); i++ {
+		prev, cur := captures[i-1], captures[i]
+		if cur.Timestamp.Sub(prev.Timestamp) > gapThreshold {
+			h.Gaps = append(h.Gaps, CaptureGap{Start: prev.Timestamp, End: cur.Timestamp})
+		}
+		if cur.Digest != lastChange.Digest {
+			changes = append(changes, cur.Timestamp.Sub(lastChange.Timestamp))
+			lastChange = cur
+		}
+	}
+	if now.Sub(h.LastCapture) > gapThreshold {
+		h.Gaps = append(h.Gaps, CaptureGap{Start: h.LastCapture, End: now, Ongoing: true})
+	}
+
+	if len(changes) > 0 {
+		sort.Slice(changes, func(i, j int) bool { return changes[i] < changes[j] })
+		h.ChangeInterval = changes[len(changes)/2]
+		h.SuggestedCadence = h.ChangeInterval
+		if h.SuggestedCadence < minSuggestedCadence {
+			h.SuggestedCadence = minSuggestedCadence
+		}
+		if gapThreshold > 0 && h.SuggestedCadence > gapThreshold {
+			h.SuggestedCadence = gapThreshold
+		}
+	}
+	return h
+}
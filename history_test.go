@@ -0,0 +1,48 @@
+package archiveorg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAnalyzeCaptureHistory(t *testing.T) {
+	day := func(month, d int) time.Time {
+		return time.Date(2024, time.Month(month), d, 0, 0, 0, 0, time.UTC)
+	}
+	captures := []CaptureChange{
+		{Timestamp: day(1, 1), Digest: "A"},
+		{Timestamp: day(1, 11), Digest: "B"},
+		{Timestamp: day(1, 21), Digest: "C"},
+		{Timestamp: day(4, 1), Digest: "C"},
+		{Timestamp: day(4, 11), Digest: "D"},
+	}
+	sparkline := ArchiveOrgWaybackSparklineResponse{
+		Years: map[string][]int{"2024": {6, 0, 0, 3, 0, 0, 0, 0, 0, 0, 0, 0}},
+	}
+	now := day(6, 30)
+
+	h := analyzeCaptureHistory(captures, sparkline, 30*24*time.Hour, now)
+	if h.Captures != 9 {
+		t.Errorf("got %v captures, want 9", h.Captures)
+	}
+	if h.CapturesPerMonth != 1.5 {
+		t.Errorf("got %v captures per month, want 1.5", h.CapturesPerMonth)
+	}
+	if len(h.Gaps) != 2 {
+		t.Fatalf("got gaps %+v, want 2", h.Gaps)
+	}
+	if !h.Gaps[0].Start.Equal(day(1, 21)) || !h.Gaps[0].End.Equal(day(4, 1)) || h.Gaps[0].Ongoing {
+		t.Errorf("unexpected first gap: %+v", h.Gaps[0])
+	}
+	if !h.Gaps[1].Ongoing || !h.Gaps[1].End.Equal(now) {
+		t.Errorf("expected an ongoing gap up to now, got %+v", h.Gaps[1])
+	}
+	if want := 10 * 24 * time.Hour; h.ChangeInterval != want || h.SuggestedCadence != want {
+		t.Errorf("change interval %v and cadence %v, want %v", h.ChangeInterval, h.SuggestedCadence, want)
+	}
+
+	never := analyzeCaptureHistory(nil, ArchiveOrgWaybackSparklineResponse{}, 30*24*time.Hour, now)
+	if never.SuggestedCadence != 30*24*time.Hour || never.Captures != 0 {
+		t.Errorf("unexpected history for a url that was never captured: %+v", never)
+	}
+}
@@ -0,0 +1,195 @@
+package archiveorg
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/url"
+	"strings"
+)
+
+// The longest line ReadURLs accepts in a newline-delimited list.
+const maxURLLineBytes int = 1 << 20
+
+// URLListFormat describes how a list of URLs is laid out.
+type URLListFormat struct {
+	// Read CSV instead of one URL per line.
+	CSV bool
+	// The CSV column the URLs are in, counting from 0.
+	Column int
+	// Skip the first CSV record because it's a header.
+	Header bool
+}
+
+// InvalidURLError is yielded for entries in a URL list that aren't
+// archivable URLs.
+type InvalidURLError struct {
+	URL string
+	// The line (or CSV record) of the list the URL was on, counting from 1.
+	Line int
+	Err  error
+}
+
+// Error returns where the invalid URL was and why it's invalid.
+func (e *InvalidURLError) Error() string {
+	return fmt.Sprintf("invalid url %q on line %v: %v", e.URL, e.Line, e.Err)
+}
+
+func (e *InvalidURLError) Unwrap() error {
+	return e.Err
+}
+
+// ReadURLs streams URLs from a newline-delimited or CSV list, normalizing
+// each one. In newline-delimited lists, blank lines and lines starting with
+// "#" are skipped. Invalid URLs are yielded as an *InvalidURLError and
+// reading continues; any other error is yielded once and reading stops.
+// Only one line is held in memory at a time.
+func ReadURLs(r io.Reader, format URLListFormat) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		next := lineReader(r)
+		if format.CSV {
+			next = csvReader(r, format)
+		}
+
+		for line := 1; ; line++ {
+			raw, skip, err := next()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				yield("", fmt.Errorf("error reading url list: %w", err))
+				return
+			}
+			if skip {
+				continue
+			}
+
+			u, err := normalizeURL(raw)
+			if err != nil {
+				err = &InvalidURLError{URL: raw, Line: line, Err: err}
+			}
+			if !yield(u, err) {
+				return
+			}
+		}
+	}
+}
+
+// lineReader returns a function that reads the next URL from a
+// newline-delimited list.
+func lineReader(r io.Reader) func() (raw string, skip bool, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxURLLineBytes)
+	return func() (string, bool, error) {
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return "", false, err
+			}
+			return "", false, io.EOF
+		}
+		raw := strings.TrimSpace(scanner.Text())
+		return raw, raw == "" || strings.HasPrefix(raw, "#"), nil
+	}
+}
+
+// csvReader returns a function that reads the next URL from a CSV list.
+func csvReader(r io.Reader, format URLListFormat) func() (raw string, skip bool, err error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+	first := true
+	return func() (string, bool, error) {
+		record, err := reader.Read()
+		if err != nil {
+			return "", false, err
+		}
+		if first && format.Header {
+			first = false
+			return "", true, nil
+		}
+		first = false
+		if format.Column >= len(record) {
+			return "", true, nil
+		}
+		raw := strings.TrimSpace(record[format.Column])
+		return raw, raw == "", nil
+	}
+}
+
+// normalizeURL checks raw is an archivable URL and returns it in a
+// consistent form: with a scheme (https if missing), a lowercase host
+// and no fragment.
+func normalizeURL(raw string) (string, error) {
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("unsupported url scheme: %v", u.Scheme)
+	}
+	if u.Hostname() == "" || strings.ContainsAny(u.Host, " \t") {
+		return "", fmt.Errorf("missing or invalid host")
+	}
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+	return u.String(), nil
+}
+
+// Archives every URL in a newline-delimited or CSV list (see ReadURLs),
+// yielding each result as soon as it's ready. The list is read as the caller
+// ranges over the results, so even huge lists aren't loaded into memory.
+// Invalid URLs are yielded as results with an *InvalidURLError.
+// Iteration stops early if ctx is cancelled.
+// Needs authentication (cookie).
+func ArchiveFromReader(ctx context.Context, r io.Reader, format URLListFormat, opts ...CallOption) iter.Seq[BatchResult] {
+	return defaultClient.ArchiveFromReader(ctx, r, format, opts...)
+}
+
+// ArchiveFromReader is the Client version of ArchiveFromReader.
+func (c *Client) ArchiveFromReader(ctx context.Context, r io.Reader, format URLListFormat, opts ...CallOption) iter.Seq[BatchResult] {
+	opts = append([]CallOption{withContext(ctx)}, opts...)
+	return func(yield func(BatchResult) bool) {
+		var readErr error
+		stopped := false
+		urls := func(yieldURL func(string) bool) {
+			for u, err := range ReadURLs(r, format) {
+				if ctx.Err() != nil {
+					readErr = ctx.Err()
+					return
+				}
+				var invalid *InvalidURLError
+				if errors.As(err, &invalid) {
+					if !yield(BatchResult{URL: invalid.URL, Err: err}) {
+						stopped = true
+						return
+					}
+					continue
+				}
+				if err != nil {
+					readErr = err
+					return
+				}
+				if !yieldURL(u) {
+					return
+				}
+			}
+		}
+
+		for result := range c.BatchResults(urls, true, opts...) {
+			if !yield(result) {
+				return
+			}
+		}
+		if readErr != nil && !stopped {
+			yield(BatchResult{Err: readErr})
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package archiveorg
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReadURLs(t *testing.T) {
+	lines := `# urls to archive
+https://Example.COM/page#section
+
+example.org/about
+ftp://example.net/file
+`
+	csvList := `name,link
+Example,https://example.com/
+"Quoted, name",example.org
+Short row
+`
+	cases := []struct {
+		name    string
+		list    string
+		format  URLListFormat
+		want    []string
+		invalid int
+	}{
+		{"lines", lines, URLListFormat{}, []string{"https://example.com/page", "https://example.org/about"}, 1},
+		{"csv", csvList, URLListFormat{CSV: true, Column: 1, Header: true}, []string{"https://example.com/", "https://example.org"}, 0},
+	}
+
+	for _, c := range cases {
+		var got []string
+		invalid := 0
+		for u, err := range ReadURLs(strings.NewReader(c.list), c.format) {
+			var invalidErr *InvalidURLError
+			if errors.As(err, &invalidErr) {
+				invalid++
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%v: error reading urls: %v", c.name, err)
+			}
+			got = append(got, u)
+		}
+		if strings.Join(got, " ") != strings.Join(c.want, " ") {
+			t.Errorf("%v: got %v, want %v", c.name, got, c.want)
+		}
+		if invalid != c.invalid {
+			t.Errorf("%v: got %v invalid urls, want %v", c.name, invalid, c.invalid)
+		}
+	}
+}
+
+func TestArchiveFromReader(t *testing.T) {
+	var saved []string
+	c := stubClient(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Path == "/save/" {
+			saved = append(saved, r.URL.Query().Get("url"))
+			return stubResponse(r, http.StatusOK, `{"url":"https://example.com/","job_id":"spn2-abc"}`)
+		}
+		return stubResponse(r, http.StatusOK, `{"status":"success","job_id":"spn2-abc","timestamp":"20200101000000"}`)
+	})
+	opts := WithArchiveOptions(ArchiveOptions{Poller: &JobPoller{Clock: &fakeClock{}}})
+	errRead := errors.New("disk on fire")
+	list := func(lines string) io.Reader {
+		return io.MultiReader(strings.NewReader(lines), iotest.ErrReader(errRead))
+	}
+
+	var results []BatchResult
+	for result := range c.ArchiveFromReader(context.Background(), list("https://example.com\nftp://example.net/file\nexample.org\n"), URLListFormat{}, opts) {
+		results = append(results, result)
+	}
+	if len(results) != 4 {
+		t.Fatalf("got %v results, want 4: %+v", len(results), results)
+	}
+	var invalid *InvalidURLError
+	if results[0].Err != nil || results[0].ArchivedURL == "" || !errors.As(results[1].Err, &invalid) || results[2].Err != nil {
+		t.Errorf("unexpected results: %+v", results)
+	}
+	if !errors.Is(results[3].Err, errRead) {
+		t.Errorf("last result is %+v, want the read error", results[3])
+	}
+
+	// The read error isn't yielded after the caller stops
+	results = nil
+	for result := range c.ArchiveFromReader(context.Background(), list("ftp://example.net/file\nhttps://example.com\n"), URLListFormat{}, opts) {
+		results = append(results, result)
+		break
+	}
+	if len(results) != 1 || !errors.As(results[0].Err, &invalid) {
+		t.Errorf("unexpected results after stopping: %+v", results)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	saved, results = nil, nil
+	for result := range c.ArchiveFromReader(ctx, list("https://example.com\nhttps://example.org\n"), URLListFormat{}, opts) {
+		results = append(results, result)
+		cancel()
+	}
+	if len(results) != 2 || results[0].Err != nil || !errors.Is(results[1].Err, context.Canceled) {
+		t.Errorf("unexpected results after cancelling: %+v", results)
+	}
+	if len(saved) != 1 {
+		t.Errorf("archived %v after cancelling, want only the first url", saved)
+	}
+}
@@ -2,20 +2,21 @@ package archiveorg
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 	"io/ioutil"
+	"iter"
 	"net/http"
 	"net/url"
+	"slices"
 	"time"
-
-	"github.com/avast/retry-go"
 )
 
 const (
-	archiveApi          string = "https://wwwb-api.archive.org"
-	archiveRoot         string = "https://web.archive.org/web"
-	pendingRetryAttemps uint   = 40
+	archiveApi  string = "https://wwwb-api.archive.org"
+	archiveRoot string = "https://web.archive.org/web"
+	// Wayback Machine timestamps are UTC, down to the second.
+	timestampLayout string = "20060102150405"
 )
 
 type ArchiveOrgWaybackAvailableResponse struct {
@@ -35,7 +36,7 @@ type ArchiveOrgWaybackSaveResponse struct {
 	JobID     string `json:"job_id"`
 	Message   string `json:"message"`
 	Status    string `json:"status,omitempty"`
-	StatusExt string `json:"statux_ext,omitempty"`
+	StatusExt string `json:"status_ext,omitempty"`
 }
 
 type ArchiveOrgWaybackStatusResponse struct {
@@ -51,6 +52,8 @@ type ArchiveOrgWaybackStatusResponse struct {
 	Outlinks     []string `json:"outlinks"`
 	Resources    []string `json:"resources"`
 	Status       string   `json:"status"`
+	StatusExt    string   `json:"status_ext,omitempty"`
+	Message      string   `json:"message,omitempty"`
 	Timestamp    string   `json:"timestamp"`
 }
 
@@ -61,6 +64,88 @@ type ArchiveOrgWaybackSparklineResponse struct {
 	Status  map[string]string `json:"status"`
 }
 
+// UnmarshalJSON decodes the response leniently; see decodeObject.
+func (r *ArchiveOrgWaybackAvailableResponse) UnmarshalJSON(data []byte) error {
+	o, err := decodeObject(data)
+	if err != nil || o == nil {
+		return err
+	}
+	*r = ArchiveOrgWaybackAvailableResponse{URL: o.string("url")}
+	// Sent as [] when there are no snapshots
+	closest := o.object("archived_snapshots").object("closest")
+	r.ArchivedSnapshots.Closest.Status = closest.string("status")
+	r.ArchivedSnapshots.Closest.Available = closest.bool("available")
+	r.ArchivedSnapshots.Closest.URL = closest.string("url")
+	r.ArchivedSnapshots.Closest.Timestamp = closest.string("timestamp")
+	return nil
+}
+
+// UnmarshalJSON decodes the response leniently; see decodeObject.
+func (r *ArchiveOrgWaybackSaveResponse) UnmarshalJSON(data []byte) error {
+	o, err := decodeObject(data)
+	if err != nil || o == nil {
+		return err
+	}
+	*r = ArchiveOrgWaybackSaveResponse{
+		URL:       o.string("url"),
+		JobID:     o.string("job_id"),
+		Message:   o.string("message"),
+		Status:    o.string("status"),
+		StatusExt: o.string("status_ext"),
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes the response leniently; see decodeObject.
+func (r *ArchiveOrgWaybackStatusResponse) UnmarshalJSON(data []byte) error {
+	o, err := decodeObject(data)
+	if err != nil || o == nil {
+		return err
+	}
+	*r = ArchiveOrgWaybackStatusResponse{
+		DurationSec:  o.float32("duration_sec"),
+		FirstArchive: o.bool("first_archive"),
+		HttpStatus:   o.int("http_status"),
+		JobID:        o.string("job_id"),
+		OriginalURL:  o.string("original_url"),
+		Outlinks:     o.strings("outlinks"),
+		Resources:    o.strings("resources"),
+		Status:       o.string("status"),
+		StatusExt:    o.string("status_ext"),
+		Message:      o.string("message"),
+		Timestamp:    o.string("timestamp"),
+	}
+	counters := o.object("counters")
+	r.Counters.Embeds = counters.int("embeds")
+	r.Counters.Outlinks = counters.int("outlinks")
+	return nil
+}
+
+// UnmarshalJSON decodes the response leniently; see decodeObject.
+func (r *ArchiveOrgWaybackSparklineResponse) UnmarshalJSON(data []byte) error {
+	o, err := decodeObject(data)
+	if err != nil || o == nil {
+		return err
+	}
+	*r = ArchiveOrgWaybackSparklineResponse{
+		FirstTs: o.string("first_ts"),
+		LastTs:  o.string("last_ts"),
+	}
+	if years := o.object("years"); years != nil {
+		r.Years = make(map[string][]int, len(years))
+		for year, counts := range years {
+			r.Years[year] = rawInts(counts)
+		}
+	}
+	if status := o.object("status"); status != nil {
+		r.Status = make(map[string]string, len(status))
+		for year, s := range status {
+			r.Status[year] = rawString(s)
+		}
+	}
+	return nil
+}
+
 type RetriableError struct {
 	Err        error
 	RetryAfter time.Duration
@@ -73,109 +158,323 @@ func (e *RetriableError) Error() string {
 
 // Checks if a page is available in the Wayback Machine.
 // r.ArchivedSnapshots will be populated if it is.
-func CheckURLWaybackAvailable(url string, retryAttempts uint) (r ArchiveOrgWaybackAvailableResponse, err error) {
-	resp := http.Response{}
-	if err := retry.Do(func() error {
-		client := http.Client{}
-		respTry, err := client.Get(archiveApi + "/wayback/available?url=" + url)
+func CheckURLWaybackAvailable(url string, opts ...CallOption) (r ArchiveOrgWaybackAvailableResponse, err error) {
+	return defaultClient.CheckURLWaybackAvailable(url, opts...)
+}
+
+// CheckURLWaybackAvailable is the Client version of CheckURLWaybackAvailable.
+func (c *Client) CheckURLWaybackAvailable(url string, opts ...CallOption) (r ArchiveOrgWaybackAvailableResponse, err error) {
+	o := c.callOptions(opts)
+	ctx, cancel := o.context()
+	defer cancel()
+	if err := c.retry(ctx, o.retries, func() error {
+		resp, err := c.get(ctx, archiveApi+"/wayback/available?url="+url)
 		if err != nil {
 			return &RetriableError{
 				Err:        fmt.Errorf("error calling archive.org wayback api: %w", err),
 				RetryAfter: 1 * time.Second,
 			}
 		}
+		defer resp.Body.Close()
 		if resp.StatusCode == 429 {
-			return fmt.Errorf("rate limited by archive.org wayback api")
-		}
-		resp = *respTry
-		return nil
-	},
-		retry.Attempts(retryAttempts),
-		retry.Delay(1*time.Second),
-		retry.DelayType(retry.FixedDelay),
-	); err != nil {
-		// retry returns a pretty human-readable error message
-		return r, err
-	} else {
-		defer func() {
-			if err := resp.Body.Close(); err != nil {
-				panic(err)
+			return &RetriableError{
+				Err:        fmt.Errorf("%w by archive.org wayback api", ErrRateLimited),
+				RetryAfter: retryAfter(resp, 0),
 			}
-		}()
-		body, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return r, fmt.Errorf("error reading body from wayback api: %w", err)
 		}
 
-		err = json.Unmarshal(body, &r)
+		body, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
-			return r, fmt.Errorf("error unmarshalling json: %w, body: %v", err, string(body))
+			return fmt.Errorf("error reading body from wayback api: %w", err)
 		}
-
-		return r, nil
+		return unmarshalResponse(resp, body, &r)
+	}); err != nil {
+		return r, err
 	}
+
+	return r, nil
 }
 
 // GetLatestUrl returns the latest archive.org link for a given URL.
-// Cookie can be blank but then this will only be successful
-// if there's an archived page already.
-func GetLatestURL(url string, retryAttempts uint, requestArchive bool, cookie string) (latestUrl string, err error) {
+// The cookie (see WithCookie) can be blank but then this will only be
+// successful if there's an archived page already. Pages archived with
+// DelayAvailability may not resolve for hours; BatchResults reports when
+// that's the case.
+func GetLatestURL(url string, requestArchive bool, opts ...CallOption) (latestUrl string, err error) {
+	return defaultClient.GetLatestURL(url, requestArchive, opts...)
+}
+
+// GetLatestURL is the Client version of GetLatestURL.
+func (c *Client) GetLatestURL(url string, requestArchive bool, opts ...CallOption) (latestUrl string, err error) {
+	r := c.latestResult(url, requestArchive, opts)
+	return r.ArchivedURL, r.Err
+}
+
+func (c *Client) latestResult(url string, requestArchive bool, opts []CallOption) BatchResult {
+	o := c.callOptions(opts)
+	ctx, cancel := o.context()
+	defer cancel()
+	opts = nested(ctx, opts)
+
 	closestURL := ""
 	if !requestArchive {
-		r, err := CheckURLWaybackAvailable(url, retryAttempts)
+		r, err := c.CheckURLWaybackAvailable(url, opts...)
 		if err != nil {
-			return "", fmt.Errorf("error checking if url is available: %w", err)
+			return BatchResult{URL: url, Err: fmt.Errorf("error checking if url is available: %w", err)}
 		}
 
 		closestURL = r.ArchivedSnapshots.Closest.URL
+
+		if closestURL != "" && o.verifySnapshots {
+			closestURL, err = c.verifyLatestURL(ctx, o.retries, url, closestURL)
+			if err != nil {
+				return BatchResult{URL: url, Err: fmt.Errorf("error verifying snapshot: %w", err)}
+			}
+		}
+
+		if closestURL == "" && o.fallbackArchives {
+			// The aggregator failing just means there's no other capture
+			// to use, which is no reason not to archive the page
+			if m, err := c.CheckMementoAggregator(url, time.Now(), opts...); err == nil {
+				closestURL = m.BestURL()
+			}
+		}
 	}
 
 	if closestURL == "" {
-		archiveUrl, err := ArchiveURL(url, retryAttempts, cookie)
-		if err != nil {
-			return "", fmt.Errorf("unable to archive URL: %w", err)
+		r := c.ArchiveURLResult(url, opts...)
+		if r.Err != nil {
+			return BatchResult{URL: url, Err: fmt.Errorf("unable to archive URL: %w", r.Err)}
 		}
 		// At this point, even if the URL is blank we should return it.
-		closestURL = archiveUrl
+		return BatchResult{URL: url, ArchivedURL: r.ArchivedURL, AvailabilityDelayed: r.AvailabilityDelayed}
 	}
 
-	return closestURL, nil
+	return BatchResult{URL: url, ArchivedURL: closestURL}
 }
 
 // Takes a slice of strings and a boolean whether or not to archive the page if not found
 // and returns a slice of strings of archive.org URLs and any errors.
-// Cookie can be blank but then this will only be successful
-// if there's an archived page already.
-func GetLatestURLs(urls []string, retryAttempts uint, requestArchive bool, cookie string) (archiveUrls []string, errs []error) {
-	for _, url := range urls {
-		var err error
-		archiveUrl, err := GetLatestURL(url, retryAttempts, requestArchive, cookie)
-		if err != nil {
-			errs = append(errs, err)
+// The cookie (see WithCookie) can be blank but then this will only be
+// successful if there's an archived page already.
+func GetLatestURLs(urls []string, requestArchive bool, opts ...CallOption) (archiveUrls []string, errs []error) {
+	return defaultClient.GetLatestURLs(urls, requestArchive, opts...)
+}
+
+// GetLatestURLs is the Client version of GetLatestURLs.
+func (c *Client) GetLatestURLs(urls []string, requestArchive bool, opts ...CallOption) (archiveUrls []string, errs []error) {
+	for result := range c.BatchResults(slices.Values(urls), requestArchive, opts...) {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
 			continue
 		}
-		archiveUrls = append(archiveUrls, archiveUrl)
+		archiveUrls = append(archiveUrls, result.ArchivedURL)
 	}
 
 	return archiveUrls, errs
 }
 
+type BatchResult struct {
+	URL         string
+	ArchivedURL string
+	Err         error
+	// Set when the URL had to be archived and the capture was requested
+	// with DelayAvailability, meaning ArchivedURL may not resolve for
+	// up to 12 hours.
+	AvailabilityDelayed bool
+}
+
+// Like GetLatestURLs, but yields each URL's result as soon as it's ready
+// instead of collecting them. URLs are only processed as the caller ranges
+// over the results, so breaking out of the loop stops the batch.
+func BatchResults(urls iter.Seq[string], requestArchive bool, opts ...CallOption) iter.Seq[BatchResult] {
+	return defaultClient.BatchResults(urls, requestArchive, opts...)
+}
+
+// BatchResults is the Client version of BatchResults.
+func (c *Client) BatchResults(urls iter.Seq[string], requestArchive bool, opts ...CallOption) iter.Seq[BatchResult] {
+	// Options given later win, so a caller's registry replaces this one
+	opts = append([]CallOption{WithJobRegistry(NewJobRegistry())}, opts...)
+	return func(yield func(BatchResult) bool) {
+		for url := range urls {
+			if !yield(c.latestResult(url, requestArchive, opts)) {
+				return
+			}
+		}
+	}
+}
+
+type ArchiveResult struct {
+	URL         string
+	ArchivedURL string
+	Err         error
+	// Set when the capture was requested with DelayAvailability,
+	// meaning ArchivedURL may not resolve for up to 12 hours.
+	AvailabilityDelayed bool
+}
+
+// ArchiveOptions are optional settings for an archive request.
+type ArchiveOptions struct {
+	// Cookie sent to the target site when capturing it, for pages behind
+	// a login. This is not the archive.org authentication cookie.
+	CaptureCookie string
+	// Credentials archive.org uses to log in to the target site.
+	TargetUsername string
+	TargetPassword string
+	// Has archive.org email the result of the capture to the account
+	// the authentication cookie belongs to.
+	EmailResult bool
+	// Makes the capture available in the Wayback Machine after about
+	// 12 hours instead of immediately, which reduces load on archive.org.
+	// The returned snapshot URL is built from the job timestamp and won't
+	// resolve until then.
+	DelayAvailability bool
+	// Controls how the capture job is polled. Defaults to DefaultJobPoller.
+	Poller *JobPoller
+}
+
+// String describes the options with any credentials redacted,
+// so options can be printed safely.
+func (o ArchiveOptions) String() string {
+	redact := func(s string) string {
+		if s == "" {
+			return ""
+		}
+		return "REDACTED"
+	}
+	return fmt.Sprintf("{CaptureCookie:%v TargetUsername:%v TargetPassword:%v EmailResult:%v DelayAvailability:%v Poller:%v}",
+		redact(o.CaptureCookie), redact(o.TargetUsername), redact(o.TargetPassword), o.EmailResult, o.DelayAvailability, o.Poller)
+}
+
+// GoString redacts credentials the same way String does.
+func (o ArchiveOptions) GoString() string {
+	return "archiveorg.ArchiveOptions" + o.String()
+}
+
+// params returns the Save Page Now parameters for the options
+// that are safe to put in a URL.
+func (o ArchiveOptions) params(archiveURL string) url.Values {
+	v := url.Values{
+		"capture_all": {"1"},
+		"url":         {archiveURL},
+	}
+	if o.EmailResult {
+		v.Set("email_result", "1")
+	}
+	if o.DelayAvailability {
+		v.Set("delay_wb_availability", "1")
+	}
+	return v
+}
+
+// credentialParams returns the Save Page Now parameters carrying
+// target site credentials. These are only ever sent in the request body
+// since URLs end up in error messages.
+func (o ArchiveOptions) credentialParams() url.Values {
+	v := url.Values{}
+	if o.CaptureCookie != "" {
+		v.Set("capture_cookie", o.CaptureCookie)
+	}
+	if o.TargetUsername != "" {
+		v.Set("target_username", o.TargetUsername)
+	}
+	if o.TargetPassword != "" {
+		v.Set("target_password", o.TargetPassword)
+	}
+	return v
+}
+
+func (o ArchiveOptions) poller() JobPoller {
+	if o.Poller != nil {
+		return *o.Poller
+	}
+	return DefaultJobPoller()
+}
+
 // Archives a given URL with archive.org. Returns an empty string and an error
-// if the URL wasn't archived.
+// if the URL wasn't archived. See WithArchiveOptions for capture settings,
+// and ArchiveURLResult to find out if availability was delayed.
+// Needs authentication (cookie).
+func ArchiveURL(archiveURL string, opts ...CallOption) (archivedURL string, err error) {
+	return defaultClient.ArchiveURL(archiveURL, opts...)
+}
+
+// ArchiveURL is the Client version of ArchiveURL.
+func (c *Client) ArchiveURL(archiveURL string, opts ...CallOption) (archivedURL string, err error) {
+	return c.archiveURL(c.callOptions(opts), archiveURL)
+}
+
+// Like ArchiveURL, but returns an ArchiveResult, which also says whether
+// the archived URL won't resolve for a while because the capture was
+// requested with DelayAvailability.
+// Needs authentication (cookie).
+func ArchiveURLResult(archiveURL string, opts ...CallOption) ArchiveResult {
+	return defaultClient.ArchiveURLResult(archiveURL, opts...)
+}
+
+// ArchiveURLResult is the Client version of ArchiveURLResult.
+func (c *Client) ArchiveURLResult(archiveURL string, opts ...CallOption) ArchiveResult {
+	return c.archiveResult(c.callOptions(opts), archiveURL)
+}
+
+// Archives a given URL with archive.org in the background. The returned
+// channel receives exactly one result and is then closed.
 // Needs authentication (cookie).
-func ArchiveURL(archiveURL string, retryAttempts uint, cookie string) (archivedURL string, err error) {
+func ArchiveURLAsync(ctx context.Context, archiveURL string, opts ...CallOption) <-chan ArchiveResult {
+	return defaultClient.ArchiveURLAsync(ctx, archiveURL, opts...)
+}
+
+// ArchiveURLAsync is the Client version of ArchiveURLAsync.
+func (c *Client) ArchiveURLAsync(ctx context.Context, archiveURL string, opts ...CallOption) <-chan ArchiveResult {
+	o := c.callOptions(append([]CallOption{withContext(ctx)}, opts...))
+	results := make(chan ArchiveResult, 1)
+	go func() {
+		defer close(results)
+		results <- c.archiveResult(o, archiveURL)
+	}()
+	return results
+}
+
+func (c *Client) archiveResult(o callOptions, archiveURL string) ArchiveResult {
+	archivedURL, err := c.archiveURL(o, archiveURL)
+	return ArchiveResult{
+		URL:                 archiveURL,
+		ArchivedURL:         archivedURL,
+		Err:                 err,
+		AvailabilityDelayed: err == nil && o.archive.DelayAvailability,
+	}
+}
+
+func (c *Client) archiveURL(o callOptions, archiveURL string) (archivedURL string, err error) {
+	if o.registry != nil {
+		registry := o.registry
+		o.registry = nil
+		archivedURL, err, _ := registry.Do(archiveURL, func() (string, error) {
+			return c.archiveURL(o, archiveURL)
+		})
+		return archivedURL, err
+	}
+
+	ctx, cancel := o.context()
+	defer cancel()
+	opts := o.archive
 	urlSnapshot := ""
-	if err := retry.Do(func() error {
-		client := &http.Client{}
-		urlParams := "capture_all=1&url=" + url.QueryEscape(archiveURL)
-		r, err := http.NewRequest(http.MethodPost, archiveApi+"/save/?"+urlParams, bytes.NewBuffer([]byte(urlParams)))
+	poller := opts.poller()
+	if err := c.retry(ctx, o.retries, func() error {
+		client := c.httpClient()
+		urlParams := opts.params(archiveURL)
+		bodyParams := opts.params(archiveURL)
+		for k, v := range opts.credentialParams() {
+			bodyParams[k] = v
+		}
+		r, err := http.NewRequestWithContext(ctx, http.MethodPost, archiveApi+"/save/?"+urlParams.Encode(), bytes.NewBufferString(bodyParams.Encode()))
 		if err != nil {
-			return fmt.Errorf("Could not build http request")
+			return fmt.Errorf("could not build http request: %w", err)
 		}
 		r.Header = http.Header{
 			"Accept":       {"application/json"},
 			"Content-Type": {"application/x-www-form-urlencoded"},
-			"Cookie":       {cookie},
+			"Cookie":       {o.cookie},
 		}
 		resp, err := client.Do(r)
 		if err != nil {
@@ -214,7 +513,12 @@ func ArchiveURL(archiveURL string, retryAttempts uint, cookie string) (archivedU
 			}
 
 			s := ArchiveOrgWaybackSaveResponse{}
-			_ = json.Unmarshal(body, &s)
+			if err := unmarshalResponse(resp, body, &s); err != nil {
+				return err
+			}
+			if s.Status == "error" {
+				return &CaptureError{Status: s.Status, StatusExt: s.StatusExt, Message: s.Message}
+			}
 			if s.JobID == "" {
 				var message string
 				if s.Message != "" {
@@ -228,44 +532,17 @@ func ArchiveURL(archiveURL string, retryAttempts uint, cookie string) (archivedU
 				}
 			}
 
-			rs, err := CheckArchiveRequestStatus(s.JobID)
+			rs, err := c.WaitForArchiveJob(ctx, s.JobID, poller)
 			if err != nil {
-				return &RetriableError{
-					Err:        fmt.Errorf("error checking archive request status: %v", string(body)),
-					RetryAfter: 3 * time.Second,
-				}
-			}
-
-			// Retry if pending
-			if rs.Status == "pending" {
-				if err := retry.Do(func() error {
-					rs, err = CheckArchiveRequestStatus(s.JobID)
-					if rs.Status == "success" {
-						return nil
-					}
-					return &RetriableError{
-						Err:        fmt.Errorf("job is still pending"),
-						RetryAfter: 3 * time.Second,
-					}
-				},
-					retry.Attempts(pendingRetryAttemps),
-					retry.Delay(1*time.Second),
-					retry.DelayType(retry.BackOffDelay),
-				); err != nil {
-					// retry returns a pretty human-readable error message
-					return err
-				}
-			}
-
-			if rs.Status != "success" {
-				return fmt.Errorf("archive.org request had unexpected status: %v", rs.Status)
+				return err
 			}
 
 			// The job returned success
 			if rs.Timestamp != "" {
 				// We could call the archive.org API again
-				// but URLs are predictable
-				urlSnapshot = archiveRoot + rs.Timestamp + archiveURL
+				// but URLs are predictable. With delayed availability
+				// the API wouldn't know about the capture yet anyway.
+				urlSnapshot = BuildSnapshotURL(rs.Timestamp, archiveURL, "")
 				return nil
 			}
 		}
@@ -273,12 +550,7 @@ func ArchiveURL(archiveURL string, retryAttempts uint, cookie string) (archivedU
 			Err:        fmt.Errorf("archive.org had unexpected http status code: %v", resp.StatusCode),
 			RetryAfter: 3 * time.Second,
 		}
-	},
-		retry.Attempts(retryAttempts),
-		retry.Delay(1*time.Second),
-		retry.DelayType(retry.BackOffDelay),
-	); err != nil {
-		// retry returns a pretty human-readable error message
+	}); err != nil {
 		return "", err
 	}
 
@@ -286,47 +558,84 @@ func ArchiveURL(archiveURL string, retryAttempts uint, cookie string) (archivedU
 	return urlSnapshot, err
 }
 
+// Waits for an archive request job to finish, checking its status
+// as often as the poller allows. Errors checking the status are
+// retried until the job stops pending.
+func WaitForArchiveJob(ctx context.Context, jobID string, poller JobPoller) (r ArchiveOrgWaybackStatusResponse, err error) {
+	return defaultClient.WaitForArchiveJob(ctx, jobID, poller)
+}
+
+// WaitForArchiveJob is the Client version of WaitForArchiveJob.
+func (c *Client) WaitForArchiveJob(ctx context.Context, jobID string, poller JobPoller) (r ArchiveOrgWaybackStatusResponse, err error) {
+	if err := poller.Poll(ctx, func() (bool, error) {
+		rs, err := c.CheckArchiveRequestStatus(jobID, withContext(ctx))
+		if err != nil && pollAgain(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		r = rs
+		return r.Status != "pending", nil
+	}); err != nil {
+		return r, fmt.Errorf("error waiting for archive request %v: %w", jobID, err)
+	}
+
+	if r.Status != "success" {
+		return r, &CaptureError{JobID: jobID, Status: r.Status, StatusExt: r.StatusExt, Message: r.Message}
+	}
+	return r, nil
+}
+
 // Checks the status of an archive request job.
-func CheckArchiveRequestStatus(jobID string) (r ArchiveOrgWaybackStatusResponse, err error) {
-	client := http.Client{}
-	resp, err := client.Get(archiveApi + "/save/status/" + jobID)
+func CheckArchiveRequestStatus(jobID string, opts ...CallOption) (r ArchiveOrgWaybackStatusResponse, err error) {
+	return defaultClient.CheckArchiveRequestStatus(jobID, opts...)
+}
+
+// CheckArchiveRequestStatus is the Client version of CheckArchiveRequestStatus.
+func (c *Client) CheckArchiveRequestStatus(jobID string, opts ...CallOption) (r ArchiveOrgWaybackStatusResponse, err error) {
+	ctx, cancel := c.callOptions(opts).context()
+	defer cancel()
+	resp, err := c.get(ctx, archiveApi+"/save/status/"+jobID)
 	if err != nil {
 		return r, fmt.Errorf("error calling archive.org status api: %w", err)
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode == 429 {
-		return r, fmt.Errorf("rate limited by archive.org status api")
+		return r, fmt.Errorf("%w by archive.org status api", ErrRateLimited)
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return r, fmt.Errorf("error reading body: %w", err)
 	}
-	err = json.Unmarshal(body, &r)
-	if err != nil {
-		return r, fmt.Errorf("error unmarshalling json: %w, body: %v", err, string(body))
-	}
-	return r, nil
+	err = unmarshalResponse(resp, body, &r)
+	return r, err
 }
 
 // Checks the sparkline (history of archived copies) for a given URL.
 // Does not need to be authenticated.
-func CheckArchiveSparkline(url string) (r ArchiveOrgWaybackSparklineResponse, err error) {
-	client := http.Client{}
-	resp, err := client.Get(archiveApi + "/__wb/sparkline/?collection=web&output=json&url=" + url)
+func CheckArchiveSparkline(url string, opts ...CallOption) (r ArchiveOrgWaybackSparklineResponse, err error) {
+	return defaultClient.CheckArchiveSparkline(url, opts...)
+}
+
+// CheckArchiveSparkline is the Client version of CheckArchiveSparkline.
+func (c *Client) CheckArchiveSparkline(url string, opts ...CallOption) (r ArchiveOrgWaybackSparklineResponse, err error) {
+	ctx, cancel := c.callOptions(opts).context()
+	defer cancel()
+	resp, err := c.get(ctx, archiveApi+"/__wb/sparkline/?collection=web&output=json&url="+url)
 	if err != nil {
 		return r, fmt.Errorf("error calling archive.org sparkline api: %w", err)
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode == 429 {
-		return r, fmt.Errorf("rate limited by archive.org sparkline api")
+		return r, fmt.Errorf("%w by archive.org sparkline api", ErrRateLimited)
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return r, fmt.Errorf("error reading body: %w", err)
 	}
-	err = json.Unmarshal(body, &r)
-	if err != nil {
-		return r, fmt.Errorf("error unmarshalling json: %w, body: %v", err, string(body))
-	}
-	return r, nil
+	err = unmarshalResponse(resp, body, &r)
+	return r, err
 }
@@ -1,13 +1,17 @@
 package archiveorg
 
 import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
 	"strings"
 	"testing"
 )
 
 func TestGetLatestURLs(t *testing.T) {
 	validUrls := []string{"https://golang.org", "https://go.dev"}
-	archiveUrls, errs := GetLatestURLs(validUrls, 1, false, "")
+	archiveUrls, errs := GetLatestURLs(validUrls, false, WithRetries(1))
 	for _, err := range errs {
 		if err != nil {
 			t.Errorf("error getting latest URLs: %v", err)
@@ -21,7 +25,7 @@ func TestGetLatestURLs(t *testing.T) {
 	}
 
 	unarchivedUrls := []string{"https://10qpwo3imdeufnenfuyfgbgbdssd.com"}
-	archiveUrls, _ = GetLatestURLs(unarchivedUrls, 1, true, "")
+	archiveUrls, _ = GetLatestURLs(unarchivedUrls, true, WithRetries(1))
 	for _, archiveUrl := range archiveUrls {
 		if strings.HasPrefix(archiveUrl, "http://web.archive.org") {
 			t.Errorf("archive.org unexpectedly has a response for %v: %v", archiveUrl, unarchivedUrls[0])
@@ -29,3 +33,81 @@ func TestGetLatestURLs(t *testing.T) {
 	}
 
 }
+
+func TestArchiveOptionsRedacted(t *testing.T) {
+	opts := ArchiveOptions{
+		CaptureCookie:  "session=hunter2",
+		TargetUsername: "alice",
+		TargetPassword: "hunter2",
+	}
+	for _, s := range []string{fmt.Sprintf("%v", opts), fmt.Sprintf("%+v", opts), fmt.Sprintf("%#v", opts)} {
+		if strings.Contains(s, "hunter2") || strings.Contains(s, "alice") {
+			t.Errorf("archive options leaked credentials: %v", s)
+		}
+	}
+
+	if got := opts.params("https://example.com").Encode(); strings.Contains(got, "hunter2") {
+		t.Errorf("credentials ended up in url params: %v", got)
+	}
+}
+
+func TestBatchResults(t *testing.T) {
+	requests := 0
+	c := stubClient(func(r *http.Request) (*http.Response, error) {
+		requests++
+		return stubResponse(r, http.StatusOK, `{"archived_snapshots":{"closest":{"available":true,"url":"http://web.archive.org/web/2020/`+r.URL.Query().Get("url")+`"}}}`)
+	})
+
+	urls := []string{"https://example.com", "https://example.org", "https://example.net"}
+	var results []BatchResult
+	for result := range c.BatchResults(slices.Values(urls), false, WithRetries(1)) {
+		if result.Err != nil {
+			t.Errorf("error getting %v: %v", result.URL, result.Err)
+		}
+		results = append(results, result)
+		if len(results) == 2 {
+			break
+		}
+	}
+	if requests != 2 {
+		t.Errorf("made %v requests after stopping at 2 results", requests)
+	}
+	if results[1].ArchivedURL != "http://web.archive.org/web/2020/https://example.org" {
+		t.Errorf("unexpected archived url: %v", results[1].ArchivedURL)
+	}
+}
+
+func TestArchiveDelayedAvailability(t *testing.T) {
+	var saves []url.Values
+	c := stubClient(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Path == "/save/" {
+			saves = append(saves, r.URL.Query())
+			return stubResponse(r, http.StatusOK, `{"url":"https://example.com/","job_id":"spn2-abc"}`)
+		}
+		return stubResponse(r, http.StatusOK, `{"status":"success","job_id":"spn2-abc","timestamp":"20200101000000"}`)
+	})
+	opts := WithArchiveOptions(ArchiveOptions{
+		EmailResult:       true,
+		DelayAvailability: true,
+		Poller:            &JobPoller{Clock: &fakeClock{}},
+	})
+
+	r := c.ArchiveURLResult("https://example.com/", opts)
+	if r.Err != nil || !r.AvailabilityDelayed {
+		t.Errorf("unexpected result: %+v", r)
+	}
+	for result := range c.BatchResults(slices.Values([]string{"https://example.org/"}), true, opts) {
+		if result.Err != nil || !result.AvailabilityDelayed {
+			t.Errorf("unexpected batch result: %+v", result)
+		}
+	}
+
+	if len(saves) != 2 {
+		t.Fatalf("made %v captures, want 2", len(saves))
+	}
+	for _, params := range saves {
+		if params.Get("email_result") != "1" || params.Get("delay_wb_availability") != "1" {
+			t.Errorf("capture options weren't sent: %v", params)
+		}
+	}
+}
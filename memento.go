@@ -0,0 +1,135 @@
+package archiveorg
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+const (
+	mementoApi string = "https://timetravel.mementoweb.org/api/json"
+)
+
+type Memento struct {
+	Datetime string   `json:"datetime"`
+	URI      []string `json:"uri"`
+}
+
+type MementoTimeTravelResponse struct {
+	OriginalURI string `json:"original_uri"`
+	Mementos    struct {
+		First   *Memento `json:"first"`
+		Prev    *Memento `json:"prev"`
+		Closest *Memento `json:"closest"`
+		Next    *Memento `json:"next"`
+		Last    *Memento `json:"last"`
+	} `json:"mementos"`
+	TimemapURI struct {
+		JSONFormat string `json:"json_format"`
+		LinkFormat string `json:"link_format"`
+		CDXJFormat string `json:"cdxj_format"`
+	} `json:"timemap_uri"`
+}
+
+// UnmarshalJSON decodes the memento leniently; see decodeObject.
+func (m *Memento) UnmarshalJSON(data []byte) error {
+	o, err := decodeObject(data)
+	if err != nil || o == nil {
+		return err
+	}
+	*m = Memento{
+		Datetime: o.string("datetime"),
+		URI:      o.strings("uri"),
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes the response leniently; see decodeObject.
+func (r *MementoTimeTravelResponse) UnmarshalJSON(data []byte) error {
+	o, err := decodeObject(data)
+	if err != nil || o == nil {
+		return err
+	}
+	*r = MementoTimeTravelResponse{OriginalURI: o.string("original_uri")}
+	mementos := o.object("mementos")
+	r.Mementos.First = mementoField(mementos, "first")
+	r.Mementos.Prev = mementoField(mementos, "prev")
+	r.Mementos.Closest = mementoField(mementos, "closest")
+	r.Mementos.Next = mementoField(mementos, "next")
+	r.Mementos.Last = mementoField(mementos, "last")
+	timemap := o.object("timemap_uri")
+	r.TimemapURI.JSONFormat = timemap.string("json_format")
+	r.TimemapURI.LinkFormat = timemap.string("link_format")
+	r.TimemapURI.CDXJFormat = timemap.string("cdxj_format")
+	return nil
+}
+
+// mementoField decodes a memento, or returns nil if the field isn't one.
+func mementoField(o jsonObject, key string) *Memento {
+	if o.object(key) == nil {
+		return nil
+	}
+	m := &Memento{}
+	m.UnmarshalJSON(o[key])
+	return m
+}
+
+// BestURL returns the most recent memento URL in the response,
+// or an empty string if there isn't one.
+func (r MementoTimeTravelResponse) BestURL() string {
+	for _, m := range []*Memento{r.Mementos.Last, r.Mementos.Closest} {
+		if m != nil && len(m.URI) > 0 {
+			return m.URI[0]
+		}
+	}
+	return ""
+}
+
+// Checks the Memento aggregator for captures of a URL in any public
+// web archive close to a given time. An aggregator 404 means no archive
+// has a capture and is not treated as an error.
+// Does not need to be authenticated.
+func CheckMementoAggregator(url string, at time.Time, opts ...CallOption) (r MementoTimeTravelResponse, err error) {
+	return defaultClient.CheckMementoAggregator(url, at, opts...)
+}
+
+// CheckMementoAggregator is the Client version of CheckMementoAggregator.
+func (c *Client) CheckMementoAggregator(url string, at time.Time, opts ...CallOption) (r MementoTimeTravelResponse, err error) {
+	o := c.callOptions(opts)
+	ctx, cancel := o.context()
+	defer cancel()
+	if err := c.retry(ctx, o.retries, func() error {
+		resp, err := c.get(ctx, mementoApi+"/"+at.UTC().Format(timestampLayout)+"/"+url)
+		if err != nil {
+			return &RetriableError{
+				Err:        fmt.Errorf("error calling memento aggregator: %w", err),
+				RetryAfter: 1 * time.Second,
+			}
+		}
+		defer resp.Body.Close()
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+		case http.StatusNotFound:
+			return nil
+		case 429:
+			return &RetriableError{
+				Err:        fmt.Errorf("%w by memento aggregator", ErrRateLimited),
+				RetryAfter: retryAfter(resp, 0),
+			}
+		default:
+			return fmt.Errorf("memento aggregator had unexpected http status code: %v", resp.StatusCode)
+		}
+
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("error reading body from memento aggregator: %w", err)
+		}
+		return unmarshalResponse(resp, body, &r)
+	}); err != nil {
+		return r, err
+	}
+
+	return r, nil
+}
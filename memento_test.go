@@ -0,0 +1,85 @@
+package archiveorg
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+const mementoFixture = `{"original_uri":"https://example.com/","mementos":{` +
+	`"closest":{"datetime":"2019-01-01T00:00:00Z","uri":["https://archive.example/2019/https://example.com/"]},` +
+	`"last":{"datetime":"2021-01-01T00:00:00Z","uri":["https://archive.example/2021/https://example.com/"]}}}`
+
+func TestCheckMementoAggregator(t *testing.T) {
+	status := http.StatusOK
+	c := stubClient(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Scheme != "https" || !strings.HasPrefix(r.URL.Path, "/api/json/20200101000000/") {
+			t.Errorf("unexpected request: %v", r.URL)
+		}
+		return stubResponse(r, status, mementoFixture)
+	})
+	at := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	r, err := c.CheckMementoAggregator("https://example.com/", at)
+	if err != nil {
+		t.Fatalf("error checking memento aggregator: %v", err)
+	}
+	if got := r.BestURL(); got != "https://archive.example/2021/https://example.com/" {
+		t.Errorf("best url %v, want the last memento", got)
+	}
+
+	status = http.StatusNotFound
+	r, err = c.CheckMementoAggregator("https://example.com/", at)
+	if err != nil {
+		t.Errorf("no captures was treated as an error: %v", err)
+	}
+	if got := r.BestURL(); got != "" {
+		t.Errorf("best url %v with no captures", got)
+	}
+}
+
+func TestGetLatestURLFallbackArchives(t *testing.T) {
+	c := stubClient(func(r *http.Request) (*http.Response, error) {
+		switch {
+		case r.URL.Path == "/wayback/available":
+			return stubResponse(r, http.StatusOK, `{"url":"https://example.com/","archived_snapshots":{}}`)
+		case strings.HasPrefix(r.URL.Path, "/api/json/"):
+			return stubResponse(r, http.StatusOK, mementoFixture)
+		}
+		t.Errorf("unexpected request: %v %v", r.Method, r.URL)
+		return stubResponse(r, http.StatusNotFound, "")
+	})
+
+	got, err := c.GetLatestURL("https://example.com/", false, WithFallbackArchives())
+	if err != nil {
+		t.Fatalf("error getting latest url: %v", err)
+	}
+	if got != "https://archive.example/2021/https://example.com/" {
+		t.Errorf("latest url %v, want the fallback archive's", got)
+	}
+}
+
+func TestGetLatestURLFallbackArchivesDown(t *testing.T) {
+	saved := 0
+	c := stubClient(func(r *http.Request) (*http.Response, error) {
+		switch {
+		case r.URL.Path == "/wayback/available":
+			return stubResponse(r, http.StatusOK, `{"url":"https://example.com/","archived_snapshots":{}}`)
+		case strings.HasPrefix(r.URL.Path, "/api/json/"):
+			return stubResponse(r, http.StatusBadRequest, "<html>Bad Request</html>")
+		case r.URL.Path == "/save/":
+			saved++
+			return stubResponse(r, http.StatusOK, `{"url":"https://example.com/","job_id":"spn2-abc"}`)
+		}
+		return stubResponse(r, http.StatusOK, `{"status":"success","job_id":"spn2-abc","timestamp":"20200101000000"}`)
+	})
+
+	got, err := c.GetLatestURL("https://example.com/", false, WithFallbackArchives(), WithRetries(1), WithArchiveOptions(ArchiveOptions{Poller: &JobPoller{Clock: &fakeClock{}}}))
+	if err != nil {
+		t.Fatalf("error getting latest url: %v", err)
+	}
+	if saved != 1 || got == "" {
+		t.Errorf("got %q after %v captures, want the page archived once", got, saved)
+	}
+}
@@ -0,0 +1,159 @@
+package archiveorg
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// The retry attempts calls get when neither the Client nor the call
+// says otherwise.
+const defaultRetryAttempts uint = 3
+
+// CallOption overrides a setting for a single call. Settings that aren't
+// overridden come from the Client.
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	ctx         context.Context
+	retries     uint
+	cookie      string
+	permaAPIKey string
+	timeout     time.Duration
+	archive     ArchiveOptions
+	// Only used by GetLatestURL and the functions built on it.
+	fallbackArchives bool
+	verifySnapshots  bool
+	// Only used by GetChangeCalendar.
+	changeThreshold float64
+	registry        *JobRegistry
+}
+
+// WithRetries sets the most times a call tries each request,
+// counting the first try. 0 means no limit other than the
+// Client's RetryPolicies.
+func WithRetries(attempts uint) CallOption {
+	return func(o *callOptions) {
+		o.retries = attempts
+	}
+}
+
+// WithCookie sets the archive.org authentication cookie sent with
+// requests that need it.
+func WithCookie(cookie string) CallOption {
+	return func(o *callOptions) {
+		o.cookie = cookie
+	}
+}
+
+// WithPermaAPIKey sets the perma.cc API key sent with requests that need it.
+func WithPermaAPIKey(apiKey string) CallOption {
+	return func(o *callOptions) {
+		o.permaAPIKey = apiKey
+	}
+}
+
+// WithTimeout limits how long a call can take in total, including
+// retries and waiting for capture jobs. 0 means no limit.
+func WithTimeout(timeout time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithArchiveOptions sets the options used when a call archives a URL.
+func WithArchiveOptions(archiveOptions ArchiveOptions) CallOption {
+	return func(o *callOptions) {
+		o.archive = archiveOptions
+	}
+}
+
+// WithFallbackArchives makes GetLatestURL query the Memento aggregator
+// for captures in other public web archives when the Wayback Machine
+// doesn't have a snapshot, before trying to archive the page. Errors from
+// the aggregator are treated as it having no captures.
+func WithFallbackArchives() CallOption {
+	return func(o *callOptions) {
+		o.fallbackArchives = true
+	}
+}
+
+// WithSnapshotVerification makes GetLatestURL check that the latest
+// Wayback Machine snapshot is an actual page, following archived
+// redirects and skipping errors and soft-404s to an earlier good
+// capture. See VerifiedSnapshot. If there's no good capture, the page
+// is treated as not archived.
+func WithSnapshotVerification() CallOption {
+	return func(o *callOptions) {
+		o.verifySnapshots = true
+	}
+}
+
+// WithChangeThreshold makes GetChangeCalendar compare the text of captures
+// instead of only their digests, so a capture only counts as changed if
+// its similarity to the last changed capture is below similarity, such as
+// 0.8. Every capture with a new digest is downloaded to compare it.
+func WithChangeThreshold(similarity float64) CallOption {
+	return func(o *callOptions) {
+		o.changeThreshold = similarity
+	}
+}
+
+// WithJobRegistry makes captures share jobs through registry, so URLs
+// that were already archived in the same run aren't archived again.
+// BatchResults and GetLatestURLs use a new registry for each batch
+// unless one is given.
+func WithJobRegistry(registry *JobRegistry) CallOption {
+	return func(o *callOptions) {
+		o.registry = registry
+	}
+}
+
+// withContext makes a call run within ctx. Calls made on behalf of another
+// call use it so they share the caller's deadline and cancellation.
+func withContext(ctx context.Context) CallOption {
+	return func(o *callOptions) {
+		o.ctx = ctx
+	}
+}
+
+// callOptions returns the client's settings with opts applied over them.
+func (c *Client) callOptions(opts []CallOption) callOptions {
+	o := callOptions{
+		ctx:         context.Background(),
+		retries:     c.RetryAttempts,
+		cookie:      c.Cookie,
+		permaAPIKey: c.PermaAPIKey,
+		timeout:     c.Timeout,
+	}
+	if o.retries == 0 {
+		o.retries = defaultRetryAttempts
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// context returns the context the call should make requests with.
+func (o callOptions) context() (context.Context, context.CancelFunc) {
+	if o.timeout > 0 {
+		return context.WithTimeout(o.ctx, o.timeout)
+	}
+	return context.WithCancel(o.ctx)
+}
+
+// nested returns opts for a call made on behalf of a call running in ctx.
+func nested(ctx context.Context, opts []CallOption) []CallOption {
+	return append(append([]CallOption{}, opts...), withContext(ctx))
+}
+
+// get makes a GET request within ctx.
+func (c *Client) get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not build http request: %w", err)
+	}
+	return c.httpClient().Do(req)
+}
@@ -0,0 +1,41 @@
+package archiveorg
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCallOptions(t *testing.T) {
+	c := NewClient()
+	c.RetryAttempts = 5
+	c.Cookie = "client-cookie"
+
+	o := c.callOptions(nil)
+	if o.retries != 5 || o.cookie != "client-cookie" {
+		t.Errorf("client defaults weren't used: retries %v, cookie %q", o.retries, o.cookie)
+	}
+
+	o = c.callOptions([]CallOption{WithRetries(1), WithCookie("call-cookie")})
+	if o.retries != 1 || o.cookie != "call-cookie" {
+		t.Errorf("call options weren't applied: retries %v, cookie %q", o.retries, o.cookie)
+	}
+
+	if o := NewClient().callOptions(nil); o.retries != defaultRetryAttempts {
+		t.Errorf("retries = %v, want %v", o.retries, defaultRetryAttempts)
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	c := stubClient(func(r *http.Request) (*http.Response, error) {
+		<-r.Context().Done()
+		return nil, r.Context().Err()
+	})
+
+	_, err := c.CheckArchiveSparkline("https://example.com", WithTimeout(10*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,257 @@
+package archiveorg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+const (
+	permaApi  string = "https://api.perma.cc/v1"
+	permaRoot string = "https://perma.cc"
+)
+
+type PermaArchiveRequest struct {
+	URL    string `json:"url"`
+	Title  string `json:"title,omitempty"`
+	Folder int    `json:"folder,omitempty"`
+}
+
+type PermaArchiveResponse struct {
+	GUID              string `json:"guid"`
+	URL               string `json:"url"`
+	Title             string `json:"title"`
+	CreationTimestamp string `json:"creation_timestamp"`
+	Detail            string `json:"detail,omitempty"`
+}
+
+type PermaCaptureJobResponse struct {
+	GUID          string  `json:"guid"`
+	Status        string  `json:"status"`
+	Message       string  `json:"message"`
+	StepCount     float32 `json:"step_count"`
+	QueuePosition int     `json:"queue_position"`
+}
+
+// UnmarshalJSON decodes the response leniently; see decodeObject.
+func (r *PermaArchiveResponse) UnmarshalJSON(data []byte) error {
+	o, err := decodeObject(data)
+	if err != nil || o == nil {
+		return err
+	}
+	*r = PermaArchiveResponse{
+		GUID:              o.string("guid"),
+		URL:               o.string("url"),
+		Title:             o.string("title"),
+		CreationTimestamp: o.string("creation_timestamp"),
+		Detail:            o.string("detail"),
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes the response leniently; see decodeObject.
+func (r *PermaCaptureJobResponse) UnmarshalJSON(data []byte) error {
+	o, err := decodeObject(data)
+	if err != nil || o == nil {
+		return err
+	}
+	*r = PermaCaptureJobResponse{
+		GUID:          o.string("guid"),
+		Status:        o.string("status"),
+		Message:       o.string("message"),
+		StepCount:     o.float32("step_count"),
+		QueuePosition: o.int("queue_position"),
+	}
+	return nil
+}
+
+// PermaProvider archives URLs with perma.cc.
+// Needs authentication (API key), and optionally the ID of the
+// folder the archive should be filed under. Zero values use
+// the client's defaults.
+type PermaProvider struct {
+	RetryAttempts uint
+	APIKey        string
+	FolderID      int
+	// Uses the default client if nil.
+	Client *Client
+}
+
+// Name returns the name of the provider.
+func (p PermaProvider) Name() string {
+	return "perma.cc"
+}
+
+// ArchiveURL archives a given URL with perma.cc.
+func (p PermaProvider) ArchiveURL(archiveURL string) (archivedURL string, err error) {
+	var opts []CallOption
+	if p.RetryAttempts > 0 {
+		opts = append(opts, WithRetries(p.RetryAttempts))
+	}
+	if p.APIKey != "" {
+		opts = append(opts, WithPermaAPIKey(p.APIKey))
+	}
+	return clientOrDefault(p.Client).PermaArchiveURL(archiveURL, p.FolderID, opts...)
+}
+
+// PermaURL returns the public perma.cc link for an archive GUID.
+func PermaURL(guid string) string {
+	return permaRoot + "/" + guid
+}
+
+// Archives a given URL with perma.cc and waits for the capture to finish.
+// Returns an empty string and an error if the URL wasn't archived.
+// Needs authentication (API key, see WithPermaAPIKey). A folderID of 0
+// uses the account's default folder. Only the Poller of
+// WithArchiveOptions is used. Creating the archive is only retried
+// when rate limited, since each attempt may use up a link.
+func PermaArchiveURL(archiveURL string, folderID int, opts ...CallOption) (archivedURL string, err error) {
+	return defaultClient.PermaArchiveURL(archiveURL, folderID, opts...)
+}
+
+// PermaArchiveURL is the Client version of PermaArchiveURL.
+func (c *Client) PermaArchiveURL(archiveURL string, folderID int, opts ...CallOption) (archivedURL string, err error) {
+	o := c.callOptions(opts)
+	ctx, cancel := o.context()
+	defer cancel()
+	opts = nested(ctx, opts)
+
+	guid := ""
+	if err := c.retry(ctx, o.retries, func() error {
+		r, err := c.CreatePermaArchive(archiveURL, folderID, opts...)
+		if err != nil && ClassifyError(err) != ErrorClassRateLimited {
+			// perma.cc may have made the link before failing, and every
+			// link made counts against the account's quota
+			return &noRetryError{Err: err}
+		}
+		if err != nil {
+			return err
+		}
+		guid = r.GUID
+		return nil
+	}); err != nil {
+		return "", err
+	}
+
+	var rs PermaCaptureJobResponse
+	if err := o.archive.poller().Poll(ctx, func() (bool, error) {
+		r, err := c.CheckPermaCaptureStatus(guid, opts...)
+		if err != nil && pollAgain(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		rs = r
+		return rs.Status != "pending" && rs.Status != "in_progress", nil
+	}); err != nil {
+		return "", fmt.Errorf("error waiting for perma.cc capture %v: %w", guid, err)
+	}
+
+	if rs.Status != "completed" {
+		return "", fmt.Errorf("perma.cc capture had unexpected status: %v (%v)", rs.Status, rs.Message)
+	}
+
+	return PermaURL(guid), nil
+}
+
+// Requests a new perma.cc archive of a URL. The capture happens
+// asynchronously; use CheckPermaCaptureStatus with the returned GUID
+// to find out when it has finished.
+func CreatePermaArchive(archiveURL string, folderID int, opts ...CallOption) (r PermaArchiveResponse, err error) {
+	return defaultClient.CreatePermaArchive(archiveURL, folderID, opts...)
+}
+
+// CreatePermaArchive is the Client version of CreatePermaArchive.
+func (c *Client) CreatePermaArchive(archiveURL string, folderID int, opts ...CallOption) (r PermaArchiveResponse, err error) {
+	o := c.callOptions(opts)
+	ctx, cancel := o.context()
+	defer cancel()
+	payload, err := json.Marshal(PermaArchiveRequest{URL: archiveURL, Folder: folderID})
+	if err != nil {
+		return r, fmt.Errorf("error marshalling json: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, permaApi+"/archives/", bytes.NewBuffer(payload))
+	if err != nil {
+		return r, fmt.Errorf("could not build http request: %w", err)
+	}
+	req.Header = http.Header{
+		"Accept":        {"application/json"},
+		"Content-Type":  {"application/json"},
+		"Authorization": {"ApiKey " + o.permaAPIKey},
+	}
+
+	client := c.httpClient()
+	resp, err := client.Do(req)
+	if err != nil {
+		return r, &RetriableError{
+			Err:        fmt.Errorf("error calling perma.cc api: %w", err),
+			RetryAfter: 3 * time.Second,
+		}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == 429 {
+		return r, &RetriableError{
+			Err:        fmt.Errorf("%w by perma.cc api", ErrRateLimited),
+			RetryAfter: retryAfter(resp, 0),
+		}
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return r, fmt.Errorf("error reading body: %w", err)
+	}
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return r, fmt.Errorf("perma.cc declined to archive the page: %w", unexpectedResponse(resp, body, nil))
+	}
+	err = unmarshalResponse(resp, body, &r)
+	if err != nil {
+		return r, err
+	}
+	if r.GUID == "" {
+		return r, fmt.Errorf("perma.cc did not respond with a guid: %w", unexpectedResponse(resp, body, nil))
+	}
+	return r, nil
+}
+
+// Checks the status of a perma.cc capture job.
+// Needs authentication (API key).
+func CheckPermaCaptureStatus(guid string, opts ...CallOption) (r PermaCaptureJobResponse, err error) {
+	return defaultClient.CheckPermaCaptureStatus(guid, opts...)
+}
+
+// CheckPermaCaptureStatus is the Client version of CheckPermaCaptureStatus.
+func (c *Client) CheckPermaCaptureStatus(guid string, opts ...CallOption) (r PermaCaptureJobResponse, err error) {
+	o := c.callOptions(opts)
+	ctx, cancel := o.context()
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, permaApi+"/user/capture_jobs/"+guid+"/", nil)
+	if err != nil {
+		return r, fmt.Errorf("could not build http request: %w", err)
+	}
+	req.Header = http.Header{
+		"Accept":        {"application/json"},
+		"Authorization": {"ApiKey " + o.permaAPIKey},
+	}
+
+	client := c.httpClient()
+	resp, err := client.Do(req)
+	if err != nil {
+		return r, fmt.Errorf("error calling perma.cc status api: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == 429 {
+		return r, fmt.Errorf("%w by perma.cc status api", ErrRateLimited)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return r, fmt.Errorf("error reading body: %w", err)
+	}
+	err = unmarshalResponse(resp, body, &r)
+	return r, err
+}
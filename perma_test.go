@@ -0,0 +1,72 @@
+package archiveorg
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestPermaArchiveURL(t *testing.T) {
+	cases := []struct {
+		name       string
+		create     int
+		statuses   []string
+		want       string
+		wantErr    string
+		wantCreate int
+	}{
+		{"success", http.StatusCreated, []string{"in_progress", "completed"}, PermaURL("ABCD-1234"), "", 1},
+		{"failed capture", http.StatusCreated, []string{"failed"}, "", "unexpected status: failed", 1},
+		{"bad request", http.StatusBadRequest, nil, "", "declined", 1},
+		{"server error", http.StatusBadGateway, nil, "", "declined", 1},
+	}
+
+	for _, tc := range cases {
+		creates, checks := 0, 0
+		c := stubClient(func(r *http.Request) (*http.Response, error) {
+			if r.Header.Get("Authorization") != "ApiKey secret" {
+				t.Errorf("%v: request sent with authorization %q", tc.name, r.Header.Get("Authorization"))
+			}
+			if r.Method == http.MethodPost && r.URL.Path == "/v1/archives/" {
+				creates++
+				return stubResponse(r, tc.create, `{"guid":"ABCD-1234","url":"https://example.com"}`)
+			}
+			if r.URL.Path == "/v1/user/capture_jobs/ABCD-1234/" && checks < len(tc.statuses) {
+				checks++
+				return stubResponse(r, http.StatusOK, `{"guid":"ABCD-1234","status":"`+tc.statuses[checks-1]+`"}`)
+			}
+			return stubResponse(r, http.StatusNotFound, `{"detail":"Not found."}`)
+		})
+
+		got, err := c.PermaArchiveURL("https://example.com", 0,
+			WithPermaAPIKey("secret"),
+			WithArchiveOptions(ArchiveOptions{Poller: &JobPoller{Clock: &fakeClock{}}}),
+		)
+		if tc.wantErr == "" && err != nil {
+			t.Errorf("%v: unexpected error: %v", tc.name, err)
+		}
+		if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+			t.Errorf("%v: error %v, want one containing %q", tc.name, err, tc.wantErr)
+		}
+		if got != tc.want {
+			t.Errorf("%v: archived url %q, want %q", tc.name, got, tc.want)
+		}
+		if creates != tc.wantCreate {
+			t.Errorf("%v: created %v archives, want %v", tc.name, creates, tc.wantCreate)
+		}
+	}
+}
+
+func TestCheckPermaCaptureStatus(t *testing.T) {
+	c := stubClient(func(r *http.Request) (*http.Response, error) {
+		return stubResponse(r, http.StatusOK, `{"guid":"ABCD-1234","status":"in_progress","step_count":2.5,"queue_position":0}`)
+	})
+
+	r, err := c.CheckPermaCaptureStatus("ABCD-1234", WithPermaAPIKey("secret"))
+	if err != nil {
+		t.Fatalf("error checking capture status: %v", err)
+	}
+	if r.Status != "in_progress" || r.StepCount != 2.5 {
+		t.Errorf("unexpected capture status: %+v", r)
+	}
+}
@@ -0,0 +1,156 @@
+package archiveorg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Polling defaults, based on archive.org's advice to wait a few seconds
+// between Save Page Now status checks and to back off for long captures.
+const (
+	defaultPollInitialDelay = 5 * time.Second
+	defaultPollMultiplier   = 1.5
+	defaultPollMaxInterval  = 30 * time.Second
+	defaultPollJitter       = 0.2
+	defaultPollMaxPending   = 10 * time.Minute
+)
+
+// ErrJobPending is returned when a job is still pending after
+// the poller's MaxPending duration.
+var ErrJobPending = errors.New("job is still pending")
+
+// Clock is the source of time for a JobPoller.
+// It's an interface so tests can use a fake clock.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// JobPoller polls an asynchronous job until it finishes, waiting
+// exponentially longer between each check. Fields other than Jitter that
+// are 0 use DefaultJobPoller's values.
+type JobPoller struct {
+	// How long to wait before the first check.
+	InitialDelay time.Duration
+	// Each wait is this many times longer than the last.
+	Multiplier float64
+	// The longest a single wait can be.
+	MaxInterval time.Duration
+	// Randomly shortens or lengthens each wait by up to this fraction
+	// (0.2 is ±20%) so many jobs don't poll in lockstep.
+	Jitter float64
+	// How long a job may stay pending before giving up.
+	// Negative means no limit.
+	MaxPending time.Duration
+	// Defaults to the system clock if nil.
+	Clock Clock
+}
+
+// DefaultJobPoller returns a JobPoller with intervals suitable for
+// Save Page Now jobs.
+func DefaultJobPoller() JobPoller {
+	return JobPoller{
+		InitialDelay: defaultPollInitialDelay,
+		Multiplier:   defaultPollMultiplier,
+		MaxInterval:  defaultPollMaxInterval,
+		Jitter:       defaultPollJitter,
+		MaxPending:   defaultPollMaxPending,
+	}
+}
+
+// Poll calls check after each wait until it reports the job is done or
+// returns an error. Returns ErrJobPending if the job is still pending after
+// MaxPending, or the context's error if it's cancelled first.
+func (p JobPoller) Poll(ctx context.Context, check func() (done bool, err error)) error {
+	p = p.withDefaults()
+	clock := p.Clock
+	if clock == nil {
+		clock = realClock{}
+	}
+
+	start := clock.Now()
+	delay := p.InitialDelay
+	for {
+		wait := p.jittered(delay)
+		if p.MaxPending > 0 {
+			remaining := p.MaxPending - clock.Now().Sub(start)
+			if remaining <= 0 {
+				return fmt.Errorf("%w after %v", ErrJobPending, p.MaxPending)
+			}
+			if wait > remaining {
+				wait = remaining
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-clock.After(wait):
+		}
+
+		done, err := check()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		delay = p.next(delay)
+	}
+}
+
+// withDefaults fills in zero fields from DefaultJobPoller, so a partly
+// configured poller can't check in a tight loop.
+func (p JobPoller) withDefaults() JobPoller {
+	d := DefaultJobPoller()
+	if p.InitialDelay <= 0 {
+		p.InitialDelay = d.InitialDelay
+	}
+	if p.Multiplier == 0 {
+		p.Multiplier = d.Multiplier
+	}
+	if p.MaxInterval == 0 {
+		p.MaxInterval = d.MaxInterval
+	}
+	if p.MaxPending == 0 {
+		p.MaxPending = d.MaxPending
+	}
+	return p
+}
+
+// pollAgain reports whether a failed status check might succeed if the
+// job is checked again, rather than meaning the job can't be checked.
+func pollAgain(err error) bool {
+	switch ClassifyError(err) {
+	case ErrorClassNetwork, ErrorClassServer, ErrorClassRateLimited:
+		return true
+	}
+	return false
+}
+
+// next returns the delay to use after d.
+func (p JobPoller) next(d time.Duration) time.Duration {
+	if p.Multiplier > 1 {
+		d = time.Duration(float64(d) * p.Multiplier)
+	}
+	if p.MaxInterval > 0 && d > p.MaxInterval {
+		d = p.MaxInterval
+	}
+	return d
+}
+
+func (p JobPoller) jittered(d time.Duration) time.Duration {
+	if p.Jitter <= 0 {
+		return d
+	}
+	return time.Duration(float64(d) * (1 + p.Jitter*(2*rand.Float64()-1)))
+}
@@ -0,0 +1,122 @@
+package archiveorg
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now   time.Time
+	waits []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.waits = append(c.waits, d)
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func TestJobPollerBackoff(t *testing.T) {
+	clock := &fakeClock{}
+	p := JobPoller{
+		InitialDelay: 1 * time.Second,
+		Multiplier:   2,
+		MaxInterval:  5 * time.Second,
+		Clock:        clock,
+	}
+
+	checks := 0
+	err := p.Poll(context.Background(), func() (bool, error) {
+		checks++
+		return checks == 5, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error polling: %v", err)
+	}
+
+	want := []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	if len(clock.waits) != len(want) {
+		t.Fatalf("waited %v, want %v", clock.waits, want)
+	}
+	for i := range want {
+		if clock.waits[i] != want[i] {
+			t.Errorf("wait %v was %v, want %v", i, clock.waits[i], want[i])
+		}
+	}
+}
+
+func TestJobPollerMaxPending(t *testing.T) {
+	clock := &fakeClock{}
+	p := JobPoller{
+		InitialDelay: 4 * time.Second,
+		Multiplier:   1,
+		MaxPending:   10 * time.Second,
+		Clock:        clock,
+	}
+
+	err := p.Poll(context.Background(), func() (bool, error) {
+		return false, nil
+	})
+	if !errors.Is(err, ErrJobPending) {
+		t.Fatalf("expected ErrJobPending, got %v", err)
+	}
+	if elapsed := clock.now.Sub(time.Time{}); elapsed != p.MaxPending {
+		t.Errorf("polled for %v, want %v", elapsed, p.MaxPending)
+	}
+}
+
+func TestJobPollerJitter(t *testing.T) {
+	p := JobPoller{Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		d := p.jittered(10 * time.Second)
+		if d < 5*time.Second || d > 15*time.Second {
+			t.Fatalf("jittered wait %v out of range", d)
+		}
+	}
+}
+
+func TestJobPollerDefaults(t *testing.T) {
+	clock := &fakeClock{}
+	p := JobPoller{MaxPending: time.Minute, Clock: clock}
+
+	checks := 0
+	err := p.Poll(context.Background(), func() (bool, error) {
+		checks++
+		return checks == 3, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error polling: %v", err)
+	}
+	want := []time.Duration{defaultPollInitialDelay, time.Duration(float64(defaultPollInitialDelay) * defaultPollMultiplier)}
+	if len(clock.waits) != 3 || clock.waits[0] != want[0] || clock.waits[1] != want[1] {
+		t.Errorf("waited %v, want %v first", clock.waits, want)
+	}
+}
+
+func TestWaitForArchiveJobErrors(t *testing.T) {
+	statuses := []int{http.StatusServiceUnavailable, http.StatusUnauthorized}
+	checks := 0
+	c := stubClient(func(r *http.Request) (*http.Response, error) {
+		status := statuses[checks]
+		checks++
+		resp, _ := stubResponse(r, status, "<html>Log in</html>")
+		resp.Header.Set("Content-Type", "text/html")
+		return resp, nil
+	})
+
+	_, err := c.WaitForArchiveJob(context.Background(), "spn2-abc", JobPoller{Clock: &fakeClock{}})
+	if !errors.Is(err, ErrUnexpectedResponse) || !strings.Contains(err.Error(), "spn2-abc") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if checks != 2 {
+		t.Errorf("checked the job %v times, want 2", checks)
+	}
+}
@@ -0,0 +1,65 @@
+package archiveorg
+
+import "fmt"
+
+// ArchiveProvider is a service that can capture a URL and return a link
+// to the archived copy.
+type ArchiveProvider interface {
+	// Name is a short human-readable name for the provider, used in errors.
+	Name() string
+	// ArchiveURL captures archiveURL and returns a link to the archived copy.
+	ArchiveURL(archiveURL string) (archivedURL string, err error)
+}
+
+// WaybackProvider archives URLs with the archive.org Wayback Machine.
+// Needs authentication (cookie). Zero values use the client's defaults.
+type WaybackProvider struct {
+	RetryAttempts uint
+	Cookie        string
+	Options       ArchiveOptions
+	// Uses the default client if nil.
+	Client *Client
+}
+
+// Name returns the name of the provider.
+func (p WaybackProvider) Name() string {
+	return "archive.org"
+}
+
+// ArchiveURL archives a given URL with archive.org.
+func (p WaybackProvider) ArchiveURL(archiveURL string) (archivedURL string, err error) {
+	return clientOrDefault(p.Client).ArchiveURL(archiveURL, p.callOptions()...)
+}
+
+// Takes a slice of URLs and archives each of them with every provider given,
+// returning a slice of archived URLs and any errors. URLs that appear more
+// than once are only archived once per provider.
+// Errors are prefixed with the name of the provider that returned them.
+func ArchiveURLs(urls []string, providers ...ArchiveProvider) (archiveUrls []string, errs []error) {
+	registry := NewJobRegistry()
+	for _, url := range urls {
+		for _, provider := range providers {
+			archiveUrl, err, _ := registry.Do(provider.Name()+" "+url, func() (string, error) {
+				return provider.ArchiveURL(url)
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%v: %w", provider.Name(), err))
+				continue
+			}
+			archiveUrls = append(archiveUrls, archiveUrl)
+		}
+	}
+
+	return archiveUrls, errs
+}
+
+func (p WaybackProvider) callOptions() []CallOption {
+	opts := []CallOption{WithArchiveOptions(p.Options)}
+	if p.RetryAttempts > 0 {
+		opts = append(opts, WithRetries(p.RetryAttempts))
+	}
+	if p.Cookie != "" {
+		opts = append(opts, WithCookie(p.Cookie))
+	}
+	return opts
+}
@@ -0,0 +1,49 @@
+package archiveorg
+
+import "sync"
+
+// JobRegistry remembers capture jobs by URL for the length of a run, so a
+// URL that comes up more than once, such as a duplicate in a batch, shares
+// one capture instead of submitting another. Callers that ask for a URL
+// while its capture is in flight wait for it and get the same result.
+// Failed captures aren't remembered, so the next caller tries again.
+// A JobRegistry is safe for concurrent use.
+type JobRegistry struct {
+	mu   sync.Mutex
+	jobs map[string]*registeredJob
+}
+
+type registeredJob struct {
+	done        chan struct{}
+	archivedURL string
+	err         error
+}
+
+// NewJobRegistry returns an empty JobRegistry.
+func NewJobRegistry() *JobRegistry {
+	return &JobRegistry{jobs: map[string]*registeredJob{}}
+}
+
+// Do returns the result of the capture registered for key, calling capture
+// to start one if there isn't one. shared is true if the result came from
+// an earlier or in-flight call.
+func (r *JobRegistry) Do(key string, capture func() (archivedURL string, err error)) (archivedURL string, err error, shared bool) {
+	r.mu.Lock()
+	if job, ok := r.jobs[key]; ok {
+		r.mu.Unlock()
+		<-job.done
+		return job.archivedURL, job.err, true
+	}
+	job := &registeredJob{done: make(chan struct{})}
+	r.jobs[key] = job
+	r.mu.Unlock()
+
+	defer close(job.done)
+	job.archivedURL, job.err = capture()
+	if job.err != nil {
+		r.mu.Lock()
+		delete(r.jobs, key)
+		r.mu.Unlock()
+	}
+	return job.archivedURL, job.err, false
+}
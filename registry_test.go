@@ -0,0 +1,63 @@
+package archiveorg
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestJobRegistry(t *testing.T) {
+	r := NewJobRegistry()
+	var captures int32
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	results := make([]string, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _, _ = r.Do("https://example.com", func() (string, error) {
+				atomic.AddInt32(&captures, 1)
+				<-release
+				return "https://web.archive.org/web/20200101000000/https://example.com", nil
+			})
+		}(i)
+	}
+	close(release)
+	wg.Wait()
+
+	if captures != 1 {
+		t.Errorf("captured %v times, want 1", captures)
+	}
+	for _, result := range results {
+		if result != results[0] {
+			t.Errorf("callers got different results: %v", results)
+		}
+	}
+
+	_, _, shared := r.Do("https://example.com", func() (string, error) {
+		t.Error("completed capture was submitted again")
+		return "", nil
+	})
+	if !shared {
+		t.Error("completed capture wasn't shared")
+	}
+}
+
+func TestJobRegistryForgetsFailures(t *testing.T) {
+	r := NewJobRegistry()
+	r.Do("https://example.com", func() (string, error) {
+		return "", errors.New("archive.org declined to archive the page")
+	})
+
+	retried := false
+	r.Do("https://example.com", func() (string, error) {
+		retried = true
+		return "", nil
+	})
+	if !retried {
+		t.Error("failed capture wasn't retried")
+	}
+}
@@ -0,0 +1,280 @@
+package archiveorg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// ErrorClass groups errors that deserve the same retry behavior.
+type ErrorClass string
+
+const (
+	// The API asked us to slow down (HTTP 429, too many captures).
+	ErrorClassRateLimited ErrorClass = "rate-limited"
+	// The request never got a response, such as DNS or connection errors.
+	ErrorClassNetwork ErrorClass = "network"
+	// archive.org is having trouble, such as 5xx or maintenance pages.
+	ErrorClassServer ErrorClass = "server"
+	// The capture ran but failed in a way that might work next time,
+	// such as the target site timing out.
+	ErrorClassCaptureFailed ErrorClass = "capture-failed"
+	// The capture was refused and won't ever succeed, such as
+	// error:blocked-url.
+	ErrorClassBlocked ErrorClass = "blocked"
+	// The capture job was still pending when the poller gave up.
+	ErrorClassPending ErrorClass = "pending"
+	// A response that wasn't what the API should send and doesn't look
+	// temporary.
+	ErrorClassUnexpected ErrorClass = "unexpected"
+	// Anything else.
+	ErrorClassOther ErrorClass = "other"
+)
+
+// ErrRateLimited is wrapped by errors returned when an API rate limits us.
+var ErrRateLimited = errors.New("rate limited")
+
+// Save Page Now status_ext values that mean a capture will never succeed.
+var blockedCaptureStatuses = map[string]bool{
+	"error:blocked":                         true,
+	"error:blocked-url":                     true,
+	"error:blocked-client-ip":               true,
+	"error:filesize-limit":                  true,
+	"error:ftp-access-denied":               true,
+	"error:invalid-host-resolution":         true,
+	"error:invalid-url-syntax":              true,
+	"error:method-not-allowed":              true,
+	"error:no-access":                       true,
+	"error:not-found":                       true,
+	"error:not-implemented":                 true,
+	"error:unauthorized":                    true,
+	"error:too-many-redirects":              true,
+	"error:bad-request":                     true,
+	"error:network-authentication-required": true,
+}
+
+// Save Page Now status_ext values that mean we're capturing too much.
+var rateLimitedCaptureStatuses = map[string]bool{
+	"error:user-session-limit":      true,
+	"error:too-many-daily-captures": true,
+	"error:host-crawling-paused":    true,
+}
+
+// Save Page Now status_ext values that mean archive.org is having trouble.
+var serverCaptureStatuses = map[string]bool{
+	"error:celery":                true,
+	"error:service-unavailable":   true,
+	"error:internal-server-error": true,
+	"error:proxy-error":           true,
+	"error:job-failed":            true,
+}
+
+// CaptureError is returned when Save Page Now reports that a capture failed.
+type CaptureError struct {
+	JobID string
+	// Usually "error".
+	Status string
+	// The specific error, such as "error:blocked-url".
+	StatusExt string
+	Message   string
+}
+
+// Error returns the status and message archive.org gave.
+func (e *CaptureError) Error() string {
+	status := e.Status
+	if e.StatusExt != "" {
+		status = e.StatusExt
+	}
+	if e.Message != "" {
+		return fmt.Sprintf("archive.org request had unexpected status: %v (%v)", status, e.Message)
+	}
+	return fmt.Sprintf("archive.org request had unexpected status: %v", status)
+}
+
+// RetryPolicy is how to retry errors of one class.
+type RetryPolicy struct {
+	// The most times to try when errors of this class happen, counting the
+	// first try. 1 means never retry, 0 means no limit other than the
+	// retry attempts given to each call.
+	Attempts uint
+	// How long to wait before the first retry after an error of this class.
+	Delay time.Duration
+	// Double the delay after each error of this class, up to MaxDelay.
+	// MaxDelay also caps how long a Retry-After header can make us wait,
+	// which is 5 minutes if it's 0.
+	Backoff  bool
+	MaxDelay time.Duration
+}
+
+// The longest a Retry-After header can make us wait when the
+// policy doesn't set a MaxDelay.
+const defaultMaxRetryAfter = 5 * time.Minute
+
+// RetryPolicies maps error classes to how to retry them.
+type RetryPolicies map[ErrorClass]RetryPolicy
+
+// DefaultRetryPolicies returns the policies a Client uses unless told
+// otherwise: waiting a long time when rate limited, retrying network
+// errors quickly and never retrying captures archive.org refused.
+func DefaultRetryPolicies() RetryPolicies {
+	return RetryPolicies{
+		ErrorClassRateLimited:   {Attempts: 5, Delay: 30 * time.Second, Backoff: true, MaxDelay: 5 * time.Minute},
+		ErrorClassNetwork:       {Attempts: 5, Delay: 1 * time.Second, Backoff: true, MaxDelay: 10 * time.Second},
+		ErrorClassServer:        {Attempts: 5, Delay: 5 * time.Second, Backoff: true, MaxDelay: 1 * time.Minute},
+		ErrorClassCaptureFailed: {Attempts: 2, Delay: 10 * time.Second},
+		ErrorClassBlocked:       {Attempts: 1},
+		ErrorClassPending:       {Attempts: 2},
+		ErrorClassUnexpected:    {Attempts: 1},
+		ErrorClassOther:         {Delay: 1 * time.Second, Backoff: true, MaxDelay: 1 * time.Minute},
+	}
+}
+
+// maxRetryAfter returns the longest a Retry-After header can make us wait.
+func (p RetryPolicy) maxRetryAfter() time.Duration {
+	if p.MaxDelay > 0 {
+		return p.MaxDelay
+	}
+	return defaultMaxRetryAfter
+}
+
+// delay returns how long to wait after the nth error of the policy's class.
+func (p RetryPolicy) delay(n uint) time.Duration {
+	d := p.Delay
+	if p.Backoff {
+		for i := uint(1); i < n; i++ {
+			d *= 2
+			if p.MaxDelay > 0 && d >= p.MaxDelay {
+				return p.MaxDelay
+			}
+		}
+	}
+	return d
+}
+
+// ClassifyError returns the class of err, which decides how it's retried.
+func ClassifyError(err error) ErrorClass {
+	var captureErr *CaptureError
+	if errors.As(err, &captureErr) {
+		switch {
+		case blockedCaptureStatuses[captureErr.StatusExt]:
+			return ErrorClassBlocked
+		case rateLimitedCaptureStatuses[captureErr.StatusExt]:
+			return ErrorClassRateLimited
+		case serverCaptureStatuses[captureErr.StatusExt]:
+			return ErrorClassServer
+		}
+		return ErrorClassCaptureFailed
+	}
+
+	var unexpected *UnexpectedResponseError
+	if errors.As(err, &unexpected) {
+		switch {
+		case unexpected.StatusCode == 429:
+			return ErrorClassRateLimited
+		case unexpected.Retriable():
+			return ErrorClassServer
+		}
+		return ErrorClassUnexpected
+	}
+
+	var netErr net.Error
+	switch {
+	case errors.Is(err, ErrRateLimited):
+		return ErrorClassRateLimited
+	case errors.Is(err, ErrJobPending):
+		return ErrorClassPending
+	case errors.As(err, &netErr):
+		return ErrorClassNetwork
+	}
+	return ErrorClassOther
+}
+
+// retryPolicy returns the client's policy for class, falling back to
+// the default policy for it.
+func (c *Client) retryPolicy(class ErrorClass) RetryPolicy {
+	if p, ok := c.RetryPolicies[class]; ok {
+		return p
+	}
+	if p, ok := DefaultRetryPolicies()[class]; ok {
+		return p
+	}
+	return DefaultRetryPolicies()[ErrorClassOther]
+}
+
+// retry calls fn until it succeeds, retrying errors as the client's
+// policy for their class says, but never more than attempts times in total
+// (0 means no limit). A RetriableError's RetryAfter is waited out if it's
+// longer than the policy's delay. Context errors are never retried.
+func (c *Client) retry(ctx context.Context, attempts uint, fn func() error) error {
+	counts := map[ErrorClass]uint{}
+	var errs []string
+	for total := uint(1); ; total++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return err
+		}
+		var noRetry *noRetryError
+		if errors.As(err, &noRetry) {
+			return noRetry.Err
+		}
+
+		class := ClassifyError(err)
+		policy := c.retryPolicy(class)
+		counts[class]++
+		errs = append(errs, fmt.Sprintf("#%v (%v): %v", total, class, err))
+		if (attempts > 0 && total >= attempts) || (policy.Attempts > 0 && counts[class] >= policy.Attempts) {
+			if total == 1 {
+				return err
+			}
+			return &RetryError{Attempts: total, Err: err, errs: errs}
+		}
+
+		delay := policy.delay(counts[class])
+		var retriable *RetriableError
+		if errors.As(err, &retriable) && retriable.RetryAfter > delay {
+			delay = min(retriable.RetryAfter, policy.maxRetryAfter())
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// noRetryError makes retry return Err without trying again, for requests
+// that aren't safe to repeat.
+type noRetryError struct {
+	Err error
+}
+
+func (e *noRetryError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *noRetryError) Unwrap() error {
+	return e.Err
+}
+
+// RetryError is returned when a call failed after more than one attempt.
+// It wraps the last error.
+type RetryError struct {
+	Attempts uint
+	Err      error
+	errs     []string
+}
+
+// Error lists the error from every attempt.
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("all %v attempts failed:\n%v", e.Attempts, strings.Join(e.errs, "\n"))
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,108 @@
+package archiveorg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClassifyError(t *testing.T) {
+	cases := map[ErrorClass]error{
+		ErrorClassBlocked:       &CaptureError{Status: "error", StatusExt: "error:blocked-url"},
+		ErrorClassRateLimited:   fmt.Errorf("%w by archive.org status api", ErrRateLimited),
+		ErrorClassCaptureFailed: &CaptureError{Status: "error", StatusExt: "error:browsing-timeout"},
+		ErrorClassServer:        &RetriableError{Err: &UnexpectedResponseError{StatusCode: http.StatusBadGateway}},
+		ErrorClassUnexpected:    &UnexpectedResponseError{StatusCode: http.StatusNotFound},
+		ErrorClassNetwork:       fmt.Errorf("error calling archive.org: %w", &net.DNSError{Err: "no such host", Name: "web.archive.org"}),
+		ErrorClassPending:       fmt.Errorf("error waiting for archive request: %w", ErrJobPending),
+		ErrorClassOther:         errors.New("something else"),
+	}
+	for want, err := range cases {
+		if got := ClassifyError(err); got != want {
+			t.Errorf("ClassifyError(%v) = %v, want %v", err, got, want)
+		}
+	}
+}
+
+func TestClientRetryPolicies(t *testing.T) {
+	c := NewClient()
+	c.RetryPolicies = RetryPolicies{
+		ErrorClassNetwork: {Attempts: 3, Delay: time.Millisecond},
+	}
+
+	calls := 0
+	err := c.retry(context.Background(), 10, func() error {
+		calls++
+		return &CaptureError{Status: "error", StatusExt: "error:blocked-url"}
+	})
+	if calls != 1 {
+		t.Errorf("blocked url was tried %v times, want 1", calls)
+	}
+	var captureErr *CaptureError
+	if !errors.As(err, &captureErr) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	calls = 0
+	err = c.retry(context.Background(), 10, func() error {
+		calls++
+		return &net.DNSError{Err: "no such host", Name: "web.archive.org"}
+	})
+	if calls != 3 {
+		t.Errorf("network error was tried %v times, want 3", calls)
+	}
+	var retryErr *RetryError
+	if !errors.As(err, &retryErr) || retryErr.Attempts != 3 {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	calls = 0
+	err = c.retry(context.Background(), 2, func() error {
+		calls++
+		return &net.DNSError{Err: "no such host", Name: "web.archive.org"}
+	})
+	if calls != 2 {
+		t.Errorf("retry attempts weren't respected: tried %v times, want 2", calls)
+	}
+
+	calls = 0
+	err = c.retry(context.Background(), 10, func() error {
+		calls++
+		if calls < 2 {
+			return &net.DNSError{Err: "no such host", Name: "web.archive.org"}
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Errorf("expected success on the second try, got %v after %v tries", err, calls)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := RetryPolicy{Delay: time.Second, Backoff: true, MaxDelay: 5 * time.Second}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		if got := p.delay(uint(i + 1)); got != w {
+			t.Errorf("delay after error %v = %v, want %v", i+1, got, w)
+		}
+	}
+}
+
+func TestRetryAfterIsCapped(t *testing.T) {
+	c := NewClient()
+	c.RetryPolicies = RetryPolicies{
+		ErrorClassRateLimited: {Attempts: 2, Delay: time.Millisecond, MaxDelay: 10 * time.Millisecond},
+	}
+
+	start := time.Now()
+	c.retry(context.Background(), 0, func() error {
+		return &RetriableError{Err: ErrRateLimited, RetryAfter: 24 * time.Hour}
+	})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("waited %v for Retry-After, want at most the policy's MaxDelay", elapsed)
+	}
+}
@@ -0,0 +1,271 @@
+package archiveorg
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// The most HTML or robots.txt CanArchive will read from a site.
+const preflightMaxBodyBytes int64 = 1 << 20
+
+// User agents archive.org crawls as, most specific first.
+var archiverUserAgents = []string{"archive.org_bot", "ia_archiver"}
+
+var (
+	metaTagRegex  = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	metaAttrRegex = regexp.MustCompile(`(?is)(name|content)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+)
+
+type robotsRule struct {
+	allow bool
+	path  string
+}
+
+type robotsGroup struct {
+	agents []string
+	rules  []robotsRule
+}
+
+// CanArchive checks a URL's robots.txt, X-Robots-Tag header and meta
+// robots tags for directives that would make archive.org decline to
+// capture it. If canArchive is false, reason says which directive was found.
+// This is a best-effort check; a true result doesn't guarantee a capture.
+func CanArchive(targetURL string, opts ...CallOption) (canArchive bool, reason string, err error) {
+	return defaultClient.CanArchive(targetURL, opts...)
+}
+
+// CanArchive is the Client version of CanArchive.
+func (c *Client) CanArchive(targetURL string, opts ...CallOption) (canArchive bool, reason string, err error) {
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return false, "", fmt.Errorf("error parsing url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false, "", fmt.Errorf("unsupported url scheme: %v", u.Scheme)
+	}
+
+	ctx, cancel := c.callOptions(opts).context()
+	defer cancel()
+	robotsURL := url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"}
+	resp, err := c.get(ctx, robotsURL.String())
+	if err != nil {
+		return false, "", fmt.Errorf("error fetching robots.txt: %w", err)
+	}
+	defer resp.Body.Close()
+	// A missing robots.txt means there are no restrictions.
+	if resp.StatusCode == http.StatusOK {
+		groups := parseRobots(io.LimitReader(resp.Body, preflightMaxBodyBytes))
+		path := u.EscapedPath()
+		if path == "" {
+			path = "/"
+		}
+		if u.RawQuery != "" {
+			path += "?" + u.RawQuery
+		}
+		if !robotsAllowed(groups, path) {
+			return false, "disallowed by robots.txt", nil
+		}
+	}
+
+	page, err := c.get(ctx, targetURL)
+	if err != nil {
+		return false, "", fmt.Errorf("error fetching page: %w", err)
+	}
+	defer page.Body.Close()
+
+	for _, header := range page.Header.Values("X-Robots-Tag") {
+		if xRobotsTagNoArchive(header) {
+			return false, "X-Robots-Tag header: " + header, nil
+		}
+	}
+
+	if !strings.Contains(page.Header.Get("Content-Type"), "html") {
+		return true, "", nil
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(page.Body, preflightMaxBodyBytes))
+	if err != nil {
+		return false, "", fmt.Errorf("error reading page body: %w", err)
+	}
+	for _, tag := range metaTagRegex.FindAll(body, -1) {
+		name, content := "", ""
+		for _, attr := range metaAttrRegex.FindAllSubmatch(tag, -1) {
+			value := string(attr[2]) + string(attr[3]) + string(attr[4])
+			switch strings.ToLower(string(attr[1])) {
+			case "name":
+				name = strings.ToLower(strings.TrimSpace(value))
+			case "content":
+				content = value
+			}
+		}
+		if name != "robots" && !isArchiverAgent(name) {
+			continue
+		}
+		if hasNoArchive(content) {
+			return false, fmt.Sprintf("meta %v tag: %v", name, content), nil
+		}
+	}
+
+	return true, "", nil
+}
+
+// hasNoArchive reports whether a robots directive list
+// (such as "noindex, noarchive") asks not to be archived. "none" only
+// means noindex and nofollow, so it doesn't count.
+func hasNoArchive(directives string) bool {
+	for _, d := range strings.FieldsFunc(strings.ToLower(directives), func(r rune) bool {
+		return r == ',' || r == ' '
+	}) {
+		if d == "noarchive" {
+			return true
+		}
+	}
+	return false
+}
+
+// Robots directives that take a value after a colon, which
+// would otherwise look like a user agent prefix.
+var robotsValueDirectives = map[string]bool{
+	"unavailable_after": true,
+	"max-snippet":       true,
+	"max-image-preview": true,
+	"max-video-preview": true,
+}
+
+// xRobotsTagNoArchive reports whether an X-Robots-Tag header asks
+// archive.org not to archive the page. Directives can be limited to one
+// crawler with a prefix ("googlebot: noarchive"), which then applies to
+// the directives after it; only unprefixed directives and ones for
+// archive.org's crawlers count.
+func xRobotsTagNoArchive(header string) bool {
+	agent := ""
+	for _, d := range strings.Split(strings.ToLower(header), ",") {
+		if name, rest, found := strings.Cut(d, ":"); found {
+			name = strings.TrimSpace(name)
+			if !robotsValueDirectives[name] && !strings.Contains(name, " ") {
+				agent, d = name, rest
+			}
+		}
+		if agent != "" && !isArchiverAgent(agent) {
+			continue
+		}
+		if hasNoArchive(d) {
+			return true
+		}
+	}
+	return false
+}
+
+func isArchiverAgent(agent string) bool {
+	for _, a := range archiverUserAgents {
+		if agent == a {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRobots parses robots.txt into groups of rules.
+func parseRobots(r io.Reader) (groups []robotsGroup) {
+	scanner := bufio.NewScanner(r)
+	var current *robotsGroup
+	inAgents := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// Consecutive user-agent lines share a group
+			if !inAgents {
+				groups = append(groups, robotsGroup{})
+				current = &groups[len(groups)-1]
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+			inAgents = true
+		case "allow", "disallow":
+			inAgents = false
+			if current == nil {
+				continue
+			}
+			// An empty disallow allows everything, so it isn't a rule
+			if value == "" {
+				continue
+			}
+			current.rules = append(current.rules, robotsRule{allow: key == "allow", path: value})
+		default:
+			inAgents = false
+		}
+	}
+	return groups
+}
+
+// robotsAllowed reports whether archive.org may crawl path according to
+// the most specific group that applies to it.
+func robotsAllowed(groups []robotsGroup, path string) bool {
+	var rules []robotsRule
+	found := false
+	agents := append([]string{}, archiverUserAgents...)
+	for _, agent := range append(agents, "*") {
+		for _, g := range groups {
+			for _, a := range g.agents {
+				if a == agent {
+					rules = append(rules, g.rules...)
+					found = true
+				}
+			}
+		}
+		if found {
+			break
+		}
+	}
+
+	// The longest matching rule wins; allow wins ties.
+	allowed, longest := true, -1
+	for _, rule := range rules {
+		if !robotsPathMatch(rule.path, path) {
+			continue
+		}
+		if len(rule.path) > longest || (len(rule.path) == longest && rule.allow) {
+			allowed, longest = rule.allow, len(rule.path)
+		}
+	}
+	return allowed
+}
+
+// robotsPathMatch matches a path against a robots.txt rule,
+// supporting the "*" and "$" wildcards.
+func robotsPathMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+	for _, part := range parts[1:] {
+		i := strings.Index(rest, part)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(part):]
+	}
+	if anchored {
+		last := parts[len(parts)-1]
+		return rest == "" || (len(parts) > 1 && strings.HasSuffix(path, last))
+	}
+	return true
+}
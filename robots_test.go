@@ -0,0 +1,58 @@
+package archiveorg
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCanArchive(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("User-agent: *\nDisallow: /private\n\nUser-agent: ia_archiver\nDisallow: /no-ia\nAllow: /no-ia/ok\n"))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/meta":
+			w.Write([]byte(`<html><head><meta name="robots" content="noindex, noarchive"></head></html>`))
+		case "/meta-none":
+			w.Write([]byte(`<html><head><meta name="robots" content="none"></head></html>`))
+		case "/header":
+			w.Header().Set("X-Robots-Tag", "noarchive")
+		case "/header-googlebot":
+			w.Header().Add("X-Robots-Tag", "googlebot: noarchive, nofollow")
+			w.Header().Add("X-Robots-Tag", "unavailable_after: 25 Jun 2030 15:00:00 PST")
+		case "/header-ia":
+			w.Header().Set("X-Robots-Tag", "googlebot: noindex, ia_archiver: noarchive")
+		default:
+			w.Write([]byte(`<html><head><meta name="description" content="noarchive"></head></html>`))
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cases := map[string]bool{
+		"/":         true,
+		"/private":  true, // only disallowed for other crawlers
+		"/no-ia":    false,
+		"/no-ia/ok": true,
+		"/meta":     false,
+		"/header":   false,
+		// only noindex, nofollow
+		"/meta-none": true,
+		// only applies to google
+		"/header-googlebot": true,
+		"/header-ia":        false,
+	}
+	for path, want := range cases {
+		got, reason, err := CanArchive(server.URL + path)
+		if err != nil {
+			t.Errorf("error checking %v: %v", path, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("CanArchive(%v) = %v (%v), want %v", path, got, reason, want)
+		}
+	}
+}
@@ -0,0 +1,358 @@
+package archiveorg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+type ScheduledURL struct {
+	URL       string    `json:"url"`
+	Added     time.Time `json:"added"`
+	Submitted time.Time `json:"submitted,omitempty"`
+	// Set once the capture has finished, successfully or not.
+	Done        bool   `json:"done,omitempty"`
+	ArchivedURL string `json:"archived_url,omitempty"`
+	// The last error, which may be retried if the URL isn't done.
+	Error string `json:"error,omitempty"`
+	// How many times the URL was requeued after being rate limited or
+	// archive.org having trouble, and when it can be submitted again.
+	Requeues int       `json:"requeues,omitempty"`
+	RetryAt  time.Time `json:"retry_at,omitempty"`
+}
+
+type schedulerState struct {
+	URLs []ScheduledURL `json:"urls"`
+	// When each recent submission started, counting resubmissions of
+	// requeued URLs, which only keep their latest Submitted time.
+	Submissions []time.Time `json:"submissions,omitempty"`
+}
+
+// How long submissions are kept in the schedule for counting
+// against the daily cap.
+const keepSubmissions = 48 * time.Hour
+
+// Scheduler archives a large list of URLs with archive.org over time,
+// spacing submissions out to stay within hourly and daily limits and
+// avoiding quiet hours. The schedule is saved to a file after every change
+// so a restarted program picks up where it left off.
+// A Scheduler is safe for concurrent use, but only one Run should be
+// active at a time.
+type Scheduler struct {
+	// Uses the default client if nil.
+	Client        *Client
+	RetryAttempts uint
+	Cookie        string
+	Options       ArchiveOptions
+
+	// Submissions are spread evenly so no more than this many start
+	// in an hour. 0 means no hourly limit.
+	PerHour int
+	// No more than this many submissions start in a calendar day.
+	// 0 means no daily limit.
+	DailyCap int
+	// No submissions start from QuietStart until QuietEnd, in hours
+	// of the day. They may wrap past midnight (22 to 6). Equal values
+	// mean there are no quiet hours.
+	QuietStart int
+	QuietEnd   int
+	// The time zone for quiet hours and days. Defaults to time.Local.
+	Location *time.Location
+	// Defaults to the system clock if nil.
+	Clock Clock
+
+	statePath string
+	mu        sync.Mutex
+	state     schedulerState
+}
+
+// NewScheduler returns a Scheduler that keeps its schedule in statePath,
+// loading the schedule from it if it already exists.
+func NewScheduler(statePath string) (*Scheduler, error) {
+	s := &Scheduler{statePath: statePath}
+	data, err := os.ReadFile(statePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading schedule: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.state); err != nil {
+		return nil, fmt.Errorf("error unmarshalling schedule: %w", err)
+	}
+	if s.state.Submissions == nil {
+		// Schedules saved before submissions were kept
+		for _, u := range s.state.URLs {
+			if !u.Submitted.IsZero() {
+				s.state.Submissions = append(s.state.Submissions, u.Submitted)
+			}
+		}
+	}
+	return s, nil
+}
+
+// Add queues URLs to be archived and saves the schedule.
+func (s *Scheduler) Add(urls ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock().Now()
+	for _, url := range urls {
+		s.state.URLs = append(s.state.URLs, ScheduledURL{URL: url, Added: now})
+	}
+	return s.save()
+}
+
+// Pending returns how many queued URLs haven't been archived yet.
+func (s *Scheduler) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := 0
+	for _, u := range s.state.URLs {
+		if !u.Done {
+			pending++
+		}
+	}
+	return pending
+}
+
+// Results returns every URL in the schedule and how archiving it went.
+func (s *Scheduler) Results() []ScheduledURL {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]ScheduledURL{}, s.state.URLs...)
+}
+
+// Run archives queued URLs, waiting between submissions as the limits
+// require, until there are none left or ctx is cancelled. A URL that was
+// submitted but didn't finish before a restart is submitted again.
+// URLs that fail because we're rate limited or archive.org is having
+// trouble are requeued with a backoff, as the client's retry policy for
+// the error's class says; other errors are final.
+func (s *Scheduler) Run(ctx context.Context) error {
+	client := clientOrDefault(s.Client)
+	for {
+		s.mu.Lock()
+		i := s.nextPending()
+		if i < 0 {
+			s.mu.Unlock()
+			return nil
+		}
+		now := s.clock().Now()
+		wait := s.nextSlot(latest(now, s.state.URLs[i].RetryAt)).Sub(now)
+		s.mu.Unlock()
+
+		if wait > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-s.clock().After(wait):
+			}
+		}
+
+		s.mu.Lock()
+		now = s.clock().Now()
+		s.state.URLs[i].Submitted = now
+		s.state.Submissions = append(slices.DeleteFunc(s.state.Submissions, func(t time.Time) bool {
+			return now.Sub(t) > keepSubmissions
+		}), now)
+		url := s.state.URLs[i].URL
+		err := s.save()
+		s.mu.Unlock()
+		if err != nil {
+			return err
+		}
+
+		archivedURL, err := client.ArchiveURL(url, s.callOptions(ctx)...)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		s.mu.Lock()
+		u := &s.state.URLs[i]
+		u.ArchivedURL = archivedURL
+		u.Error = ""
+		u.Done = true
+		if err != nil {
+			u.Error = err.Error()
+			if delay, ok := s.requeueDelay(client, err, u.Requeues+1); ok {
+				u.Requeues++
+				u.RetryAt = s.clock().Now().Add(delay)
+				u.Done = false
+			}
+		}
+		err = s.save()
+		s.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// callOptions returns the options each submission is made with. Zero
+// values use the client's defaults.
+func (s *Scheduler) callOptions(ctx context.Context) []CallOption {
+	opts := []CallOption{withContext(ctx), WithArchiveOptions(s.Options)}
+	if s.RetryAttempts > 0 {
+		opts = append(opts, WithRetries(s.RetryAttempts))
+	}
+	if s.Cookie != "" {
+		opts = append(opts, WithCookie(s.Cookie))
+	}
+	return opts
+}
+
+// requeueDelay reports whether a URL that failed with err for the nth time
+// should be submitted again, and how long to wait first.
+func (s *Scheduler) requeueDelay(client *Client, err error, n int) (delay time.Duration, ok bool) {
+	class := ClassifyError(err)
+	if class != ErrorClassRateLimited && class != ErrorClassServer {
+		return 0, false
+	}
+	policy := client.retryPolicy(class)
+	if policy.Attempts > 0 && uint(n) >= policy.Attempts {
+		return 0, false
+	}
+	delay = policy.delay(uint(n))
+	var retriable *RetriableError
+	if errors.As(err, &retriable) && retriable.RetryAfter > delay {
+		delay = min(retriable.RetryAfter, policy.maxRetryAfter())
+	}
+	return delay, true
+}
+
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+func (s *Scheduler) clock() Clock {
+	if s.Clock != nil {
+		return s.Clock
+	}
+	return realClock{}
+}
+
+func (s *Scheduler) location() *time.Location {
+	if s.Location != nil {
+		return s.Location
+	}
+	return time.Local
+}
+
+// nextPending returns the index of the URL that isn't done and can be
+// submitted soonest, or -1.
+func (s *Scheduler) nextPending() int {
+	next := -1
+	for i, u := range s.state.URLs {
+		if !u.Done && (next < 0 || u.RetryAt.Before(s.state.URLs[next].RetryAt)) {
+			next = i
+		}
+	}
+	return next
+}
+
+// nextSlot returns the earliest time at or after now that a submission
+// can start without breaking any limit.
+func (s *Scheduler) nextSlot(now time.Time) time.Time {
+	now = now.In(s.location())
+	var last time.Time
+	for _, submitted := range s.state.Submissions {
+		if submitted.After(last) {
+			last = submitted
+		}
+	}
+
+	t := now
+	if s.PerHour > 0 && !last.IsZero() {
+		if earliest := last.Add(time.Hour / time.Duration(s.PerHour)); earliest.After(t) {
+			t = earliest.In(s.location())
+		}
+	}
+
+	// Moving past quiet hours can move into the next day and vice versa,
+	// so keep going until neither applies.
+	for {
+		moved := false
+		if end, quiet := s.quietUntil(t); quiet {
+			t, moved = end, true
+		}
+		if s.DailyCap > 0 && s.submittedOn(t) >= s.DailyCap {
+			y, m, d := t.Date()
+			t, moved = time.Date(y, m, d+1, 0, 0, 0, 0, s.location()), true
+		}
+		if !moved {
+			return t
+		}
+	}
+}
+
+// quietUntil reports whether t is in quiet hours and when they end.
+func (s *Scheduler) quietUntil(t time.Time) (end time.Time, quiet bool) {
+	if s.QuietStart == s.QuietEnd {
+		return t, false
+	}
+	hour := t.Hour()
+	y, m, d := t.Date()
+	if s.QuietStart < s.QuietEnd {
+		if hour >= s.QuietStart && hour < s.QuietEnd {
+			return time.Date(y, m, d, s.QuietEnd, 0, 0, 0, s.location()), true
+		}
+		return t, false
+	}
+	// Quiet hours wrap past midnight
+	if hour >= s.QuietStart {
+		return time.Date(y, m, d+1, s.QuietEnd, 0, 0, 0, s.location()), true
+	}
+	if hour < s.QuietEnd {
+		return time.Date(y, m, d, s.QuietEnd, 0, 0, 0, s.location()), true
+	}
+	return t, false
+}
+
+// submittedOn counts the submissions started on the same day as t.
+func (s *Scheduler) submittedOn(t time.Time) (count int) {
+	y, m, d := t.Date()
+	for _, submitted := range s.state.Submissions {
+		uy, um, ud := submitted.In(s.location()).Date()
+		if uy == y && um == m && ud == d {
+			count++
+		}
+	}
+	return count
+}
+
+// save writes the schedule to a temporary file and renames it into place,
+// so a crash mid-write can't leave a corrupt schedule.
+func (s *Scheduler) save() error {
+	data, err := json.Marshal(s.state)
+	if err != nil {
+		return fmt.Errorf("error marshalling schedule: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.statePath), filepath.Base(s.statePath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error saving schedule: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error saving schedule: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error saving schedule: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.statePath); err != nil {
+		return fmt.Errorf("error saving schedule: %w", err)
+	}
+	return nil
+}
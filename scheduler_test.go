@@ -0,0 +1,179 @@
+package archiveorg
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSchedulerNextSlot(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 1, day, hour, minute, 0, 0, time.UTC)
+	}
+	s := &Scheduler{
+		PerHour:    4,
+		DailyCap:   3,
+		QuietStart: 22,
+		QuietEnd:   6,
+		Location:   time.UTC,
+	}
+
+	if got := s.nextSlot(at(1, 12, 0)); !got.Equal(at(1, 12, 0)) {
+		t.Errorf("first submission was delayed to %v", got)
+	}
+	if got := s.nextSlot(at(1, 23, 0)); !got.Equal(at(2, 6, 0)) {
+		t.Errorf("submission in quiet hours scheduled at %v, want %v", got, at(2, 6, 0))
+	}
+
+	s.state.Submissions = []time.Time{at(1, 12, 0)}
+	if got := s.nextSlot(at(1, 12, 5)); !got.Equal(at(1, 12, 15)) {
+		t.Errorf("submissions not spaced out: got %v, want %v", got, at(1, 12, 15))
+	}
+	if got := s.nextSlot(at(1, 21, 55)); !got.Equal(at(1, 21, 55)) {
+		t.Errorf("submission before quiet hours delayed to %v", got)
+	}
+
+	// Resubmissions of requeued URLs count too
+	s.state.Submissions = append(s.state.Submissions, at(1, 13, 0), at(1, 14, 0))
+	if got := s.nextSlot(at(1, 15, 0)); !got.Equal(at(2, 6, 0)) {
+		t.Errorf("daily cap not respected: got %v, want %v", got, at(2, 6, 0))
+	}
+}
+
+func TestSchedulerPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedule.json")
+	s, err := NewScheduler(path)
+	if err != nil {
+		t.Fatalf("error creating scheduler: %v", err)
+	}
+	if err := s.Add("https://example.com", "https://example.org"); err != nil {
+		t.Fatalf("error adding urls: %v", err)
+	}
+
+	restarted, err := NewScheduler(path)
+	if err != nil {
+		t.Fatalf("error loading scheduler: %v", err)
+	}
+	if restarted.Pending() != 2 {
+		t.Errorf("restarted scheduler has %v pending urls, want 2", restarted.Pending())
+	}
+	if got := restarted.Results()[1].URL; got != "https://example.org" {
+		t.Errorf("unexpected url after restart: %v", got)
+	}
+}
+
+func TestSchedulerRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedule.json")
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	saves := map[string]int{}
+	c := stubClient(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Path != "/save/" {
+			return stubResponse(r, http.StatusOK, `{"status":"success","job_id":"spn2-abc","timestamp":"20240101000000"}`)
+		}
+		page := r.URL.Query().Get("url")
+		saves[page]++
+		switch {
+		case page == "https://example.org" && saves[page] == 1:
+			return stubResponse(r, http.StatusTooManyRequests, "")
+		case page == "https://example.net" && saves[page] == 1:
+			// The program stops part-way through
+			cancel()
+		}
+		return stubResponse(r, http.StatusOK, `{"url":"`+page+`","job_id":"spn2-abc"}`)
+	})
+	newScheduler := func(clock Clock) *Scheduler {
+		s, err := NewScheduler(path)
+		if err != nil {
+			t.Fatalf("error creating scheduler: %v", err)
+		}
+		s.Client = c
+		s.RetryAttempts = 1
+		s.PerHour = 2
+		s.Location = time.UTC
+		s.Clock = clock
+		s.Options.Poller = &JobPoller{Clock: &fakeClock{}}
+		return s
+	}
+
+	clock := &fakeClock{now: start}
+	s := newScheduler(clock)
+	if err := s.Add("https://example.com", "https://example.org", "https://example.net"); err != nil {
+		t.Fatalf("error adding urls: %v", err)
+	}
+	if err := s.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("run returned %v, want it cancelled", err)
+	}
+	if want := []time.Duration{30 * time.Minute, 30 * time.Minute}; !slices.Equal(clock.waits, want) {
+		t.Errorf("waited %v between submissions, want %v", clock.waits, want)
+	}
+
+	clock = &fakeClock{now: clock.now}
+	s = newScheduler(clock)
+	results := s.Results()
+	if !results[0].Done || results[1].Done || results[1].Requeues != 1 || results[2].Done {
+		t.Fatalf("unexpected schedule after restart: %+v", results)
+	}
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatalf("error running restarted scheduler: %v", err)
+	}
+	if want := []time.Duration{30 * time.Minute, 30 * time.Minute}; !slices.Equal(clock.waits, want) {
+		t.Errorf("waited %v between submissions after restart, want %v", clock.waits, want)
+	}
+	if s.Pending() != 0 {
+		t.Errorf("%v urls still pending", s.Pending())
+	}
+	for _, u := range s.Results() {
+		if u.Error != "" || u.ArchivedURL == "" {
+			t.Errorf("unexpected result: %+v", u)
+		}
+	}
+	if saves["https://example.com"] != 1 || saves["https://example.org"] != 2 || saves["https://example.net"] != 2 {
+		t.Errorf("unexpected submissions: %v", saves)
+	}
+}
+
+func TestSchedulerRunDailyCapCountsRequeues(t *testing.T) {
+	saves := map[string]int{}
+	c := stubClient(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Path != "/save/" {
+			return stubResponse(r, http.StatusOK, `{"status":"success","job_id":"spn2-abc","timestamp":"20240101000000"}`)
+		}
+		page := r.URL.Query().Get("url")
+		saves[page]++
+		if page == "https://example.com" && saves[page] <= 2 {
+			return stubResponse(r, http.StatusTooManyRequests, "")
+		}
+		return stubResponse(r, http.StatusOK, `{"url":"`+page+`","job_id":"spn2-abc"}`)
+	})
+	s, err := NewScheduler(filepath.Join(t.TempDir(), "schedule.json"))
+	if err != nil {
+		t.Fatalf("error creating scheduler: %v", err)
+	}
+	s.Client = c
+	s.RetryAttempts = 1
+	s.DailyCap = 3
+	s.Location = time.UTC
+	s.Clock = &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	s.Options.Poller = &JobPoller{Clock: &fakeClock{}}
+	if err := s.Add("https://example.com", "https://example.org"); err != nil {
+		t.Fatalf("error adding urls: %v", err)
+	}
+
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatalf("error running scheduler: %v", err)
+	}
+	if saves["https://example.com"] != 3 || saves["https://example.org"] != 1 {
+		t.Errorf("unexpected submissions: %v", saves)
+	}
+	// Two submissions of example.com and one of example.org use up the first day
+	if got, want := s.Results()[0].Submitted, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("last submission of requeued url at %v, want %v", got, want)
+	}
+}
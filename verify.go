@@ -0,0 +1,213 @@
+package archiveorg
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// How many of the most recent captures are checked before giving up.
+	maxVerifiedCaptures int = 10
+	// How many archived redirects are followed before giving up.
+	maxVerifiedRedirects int = 5
+	// HTML pages with less text than this are treated as soft-404s if
+	// they also have no title or mention an error.
+	minPageTextLength int = 64
+)
+
+// ErrNoGoodSnapshot is returned when none of a URL's recent captures are
+// an actual page, such as when they're all errors or soft-404s.
+var ErrNoGoodSnapshot = errors.New("no good snapshot")
+
+var (
+	titleRegex = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	tagRegex   = regexp.MustCompile(`(?is)<script\b.*?</script>|<style\b.*?</style>|<[^>]*>`)
+)
+
+// Titles of error pages that are served with a 200 status.
+var soft404Titles = []string{"404", "not found", "page not found", "does not exist", "no longer available"}
+
+// Returns the most recent capture of a URL that's an actual page rather
+// than an error, an archived redirect or a soft-404 (an error page served
+// with a 200 status). Archived redirects are followed to the capture of
+// the page they lead to. Returns ErrNoGoodSnapshot if no recent capture
+// is good.
+// Does not need to be authenticated.
+func VerifiedSnapshot(pageURL string, opts ...CallOption) (s Snapshot, err error) {
+	return defaultClient.VerifiedSnapshot(pageURL, opts...)
+}
+
+// VerifiedSnapshot is the Client version of VerifiedSnapshot.
+func (c *Client) VerifiedSnapshot(pageURL string, opts ...CallOption) (s Snapshot, err error) {
+	o := c.callOptions(opts)
+	ctx, cancel := o.context()
+	defer cancel()
+	return c.verifiedSnapshot(ctx, o.retries, pageURL, map[string]bool{})
+}
+
+// verifiedSnapshot finds the latest good capture of pageURL. visited holds
+// the URLs already checked while following redirects, so redirect loops
+// are only followed once.
+func (c *Client) verifiedSnapshot(ctx context.Context, retries uint, pageURL string, visited map[string]bool) (s Snapshot, err error) {
+	visited[pageURL] = true
+	snapshots, err := c.recentSnapshots(ctx, retries, pageURL, maxVerifiedCaptures)
+	if err != nil {
+		return s, err
+	}
+
+	// Newest first
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		snapshot := snapshots[i]
+		timestamp := snapshot.Timestamp.Format(timestampLayout)
+		switch {
+		case strings.HasPrefix(snapshot.StatusCode, "3"):
+			// The first visited URL is the one asked for
+			if len(visited) > maxVerifiedRedirects {
+				continue
+			}
+			target, err := c.archivedRedirect(ctx, timestamp, snapshot.OriginalURL)
+			if err != nil || visited[target] {
+				continue
+			}
+			s, err := c.verifiedSnapshot(ctx, retries, target, visited)
+			if errors.Is(err, ErrNoGoodSnapshot) {
+				continue
+			}
+			return s, err
+		// Revisits of an earlier capture have no status of their own
+		case snapshot.StatusCode == "200", snapshot.StatusCode == "-":
+			body, _, err := c.downloadSnapshot(ctx, timestamp, snapshot.OriginalURL)
+			if errors.Is(err, ErrRateLimited) || ctx.Err() != nil {
+				return s, err
+			}
+			if err != nil || isSoft404(snapshot.MimeType, body) {
+				continue
+			}
+			return snapshot, nil
+		}
+	}
+	return s, fmt.Errorf("%w of %v in its last %v captures", ErrNoGoodSnapshot, pageURL, len(snapshots))
+}
+
+// recentSnapshots returns up to limit of the latest captures of pageURL,
+// oldest first.
+func (c *Client) recentSnapshots(ctx context.Context, retries uint, pageURL string, limit int) (snapshots []Snapshot, err error) {
+	body, err := c.queryCDX(ctx, retries, url.Values{
+		"url": {pageURL},
+		"fl":  {"timestamp,original,mimetype,statuscode,digest"},
+		// A negative limit returns the last captures instead of the first
+		"limit": {strconv.Itoa(-limit)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		s, err := snapshotFromCDX(scanner.Text())
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading cdx response: %w", err)
+	}
+	return snapshots, nil
+}
+
+// archivedRedirect returns the URL an archived redirect leads to.
+func (c *Client) archivedRedirect(ctx context.Context, timestamp string, originalURL string) (target string, err error) {
+	client := c.httpClient()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, BuildSnapshotURL(timestamp, originalURL, "id_"), nil)
+	if err != nil {
+		return "", fmt.Errorf("could not build http request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error calling archive.org: %w", err)
+	}
+	resp.Body.Close()
+
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return "", fmt.Errorf("archived redirect of %v has no location", originalURL)
+	}
+	// The Wayback Machine points redirects at its capture of the target
+	if s, err := ParseSnapshotURL(location); err == nil {
+		return s.OriginalURL, nil
+	}
+	base, err := url.Parse(originalURL)
+	if err != nil {
+		return "", fmt.Errorf("error parsing url: %w", err)
+	}
+	ref, err := url.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("error parsing redirect location: %w", err)
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
+// isSoft404 guesses whether an archived page is an error page
+// despite having been served with a 200 status.
+func isSoft404(mimeType string, body []byte) bool {
+	if len(body) == 0 {
+		return true
+	}
+	if !strings.Contains(mimeType, "html") {
+		return false
+	}
+	var title string
+	if m := titleRegex.FindSubmatch(body); m != nil {
+		title = strings.TrimSpace(string(m[1]))
+	}
+	if mentionsError(title) {
+		return true
+	}
+	text := strings.Join(strings.Fields(tagRegex.ReplaceAllString(string(body), " ")), " ")
+	if len(text) >= minPageTextLength {
+		return false
+	}
+	// Plenty of real pages, such as single-page apps, have little text
+	// until their scripts run, so short pages also need to look broken
+	return title == "" || mentionsError(text)
+}
+
+// mentionsError reports whether s contains any of soft404Titles.
+func mentionsError(s string) bool {
+	s = strings.ToLower(s)
+	for _, t := range soft404Titles {
+		if strings.Contains(s, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyLatestURL replaces closestURL with the latest good capture if
+// it's a Wayback Machine snapshot. It returns "" if there isn't one.
+func (c *Client) verifyLatestURL(ctx context.Context, retries uint, pageURL string, closestURL string) (verifiedURL string, err error) {
+	if _, err := ParseSnapshotURL(closestURL); err != nil {
+		// Only Wayback Machine captures can be checked
+		return closestURL, nil
+	}
+	s, err := c.verifiedSnapshot(ctx, retries, pageURL, map[string]bool{})
+	if errors.Is(err, ErrNoGoodSnapshot) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return s.URL, nil
+}
@@ -0,0 +1,78 @@
+package archiveorg
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestVerifiedSnapshot(t *testing.T) {
+	captures := map[string]string{
+		"https://example.com/old": "20220101000000 https://example.com/old text/html 301 AAA\n",
+		"https://example.com/new": "20200101000000 https://example.com/new text/html 200 BBB\n" +
+			"20210101000000 https://example.com/new text/html 200 CCC\n" +
+			"20220101000000 https://example.com/new text/html 404 DDD\n",
+		"https://example.com/gone": "20220101000000 https://example.com/gone text/html 200 EEE\n",
+		"https://example.com/app":  "20220101000000 https://example.com/app text/html 200 FFF\n",
+		"https://example.com/ping": "20220101000000 https://example.com/ping text/html 301 GGG\n",
+		"https://example.com/pong": "20220101000000 https://example.com/pong text/html 301 HHH\n",
+	}
+	redirects := map[string]string{
+		"https://example.com/old":  "https://example.com/new",
+		"https://example.com/ping": "https://example.com/pong",
+		"https://example.com/pong": "https://example.com/ping",
+	}
+	pages := map[string]string{
+		"20200101000000/https://example.com/new":  "<html><head><title>New</title></head><body>" + strings.Repeat("Real content. ", 10) + "</body></html>",
+		"20210101000000/https://example.com/new":  "<html><head><title>Page Not Found</title></head><body>" + strings.Repeat("Sorry. ", 20) + "</body></html>",
+		"20220101000000/https://example.com/gone": "<html><body><p>Oops</p></body></html>",
+		"20220101000000/https://example.com/app":  `<html><head><title>Dashboard</title><script src="/app.js"></script></head><body><div id="root"></div></body></html>`,
+	}
+	followed := 0
+	c := stubClient(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Path == "/cdx/search/cdx" {
+			return stubResponse(r, http.StatusOK, captures[r.URL.Query().Get("url")])
+		}
+		s, err := ParseSnapshotURL(r.URL.String())
+		if err != nil {
+			return stubResponse(r, http.StatusNotFound, "")
+		}
+		if target, ok := redirects[s.OriginalURL]; ok {
+			followed++
+			resp, _ := stubResponse(r, http.StatusMovedPermanently, "")
+			resp.Header.Set("Location", BuildSnapshotURL(s.Timestamp, target, "id_"))
+			return resp, nil
+		}
+		body, ok := pages[s.Timestamp+"/"+s.OriginalURL]
+		if !ok {
+			return stubResponse(r, http.StatusNotFound, "")
+		}
+		return stubResponse(r, http.StatusOK, body)
+	})
+
+	s, err := c.VerifiedSnapshot("https://example.com/old")
+	if err != nil {
+		t.Fatalf("error verifying snapshot: %v", err)
+	}
+	if want := BuildSnapshotURL("20200101000000", "https://example.com/new", ""); s.URL != want {
+		t.Errorf("verified snapshot is %v, want %v", s.URL, want)
+	}
+
+	if _, err := c.VerifiedSnapshot("https://example.com/gone"); !errors.Is(err, ErrNoGoodSnapshot) {
+		t.Errorf("unexpected error for soft-404: %v", err)
+	}
+
+	// Single-page apps have little text before their scripts run
+	if _, err := c.VerifiedSnapshot("https://example.com/app"); err != nil {
+		t.Errorf("unexpected error for short page: %v", err)
+	}
+
+	followed = 0
+	if _, err := c.VerifiedSnapshot("https://example.com/ping"); !errors.Is(err, ErrNoGoodSnapshot) {
+		t.Errorf("unexpected error for redirect loop: %v", err)
+	}
+	if followed != 2 {
+		t.Errorf("followed %v redirects in a loop of 2", followed)
+	}
+}
@@ -0,0 +1,50 @@
+package archiveorg
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Matches Wayback Machine snapshot URLs, such as
+// https://web.archive.org/web/20200101000000id_/https://example.com/
+var snapshotURLRegex = regexp.MustCompile(`^(?i:https?://)?(?i:web\.archive\.org)/web/(\d{1,14})([a-z]{2}_)?/(.+)$`)
+
+// Matches an original URL whose "//" has been collapsed to "/",
+// which some clients and proxies do to URLs in paths.
+var collapsedSchemeRegex = regexp.MustCompile(`^(?i)(https?):/([^/])`)
+
+type SnapshotURL struct {
+	// The snapshot's timestamp, which may be shortened (such as "2020")
+	// to mean the capture closest to it.
+	Timestamp string
+	// The replay modifier, such as "id_" for the original unmodified
+	// content or "im_" for images. Empty for the normal replay.
+	Modifier    string
+	OriginalURL string
+}
+
+// String returns the snapshot's Wayback Machine URL.
+func (s SnapshotURL) String() string {
+	return BuildSnapshotURL(s.Timestamp, s.OriginalURL, s.Modifier)
+}
+
+// BuildSnapshotURL returns the Wayback Machine URL for the snapshot of
+// originalURL at timestamp, with an optional replay modifier such as "id_".
+func BuildSnapshotURL(timestamp string, originalURL string, modifier string) string {
+	return archiveRoot + "/" + timestamp + modifier + "/" + originalURL
+}
+
+// ParseSnapshotURL splits a Wayback Machine snapshot URL into its timestamp,
+// replay modifier and original URL.
+func ParseSnapshotURL(waybackURL string) (s SnapshotURL, err error) {
+	m := snapshotURLRegex.FindStringSubmatch(strings.TrimSpace(waybackURL))
+	if m == nil {
+		return s, fmt.Errorf("not a wayback machine snapshot url: %v", waybackURL)
+	}
+	return SnapshotURL{
+		Timestamp:   m[1],
+		Modifier:    m[2],
+		OriginalURL: collapsedSchemeRegex.ReplaceAllString(m[3], "$1://$2"),
+	}, nil
+}
@@ -0,0 +1,44 @@
+package archiveorg
+
+import "testing"
+
+func TestParseSnapshotURL(t *testing.T) {
+	cases := map[string]SnapshotURL{
+		"https://web.archive.org/web/20200101000000/https://example.com/":        {"20200101000000", "", "https://example.com/"},
+		"http://web.archive.org/web/20200101000000id_/https://example.com/a?b=c": {"20200101000000", "id_", "https://example.com/a?b=c"},
+		"https://web.archive.org/web/2020im_/http:/example.com/logo.png":         {"2020", "im_", "http://example.com/logo.png"},
+		"web.archive.org/web/20200101000000/example.com":                         {"20200101000000", "", "example.com"},
+	}
+	for waybackURL, want := range cases {
+		got, err := ParseSnapshotURL(waybackURL)
+		if err != nil {
+			t.Errorf("error parsing %v: %v", waybackURL, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ParseSnapshotURL(%v) = %+v, want %+v", waybackURL, got, want)
+		}
+	}
+
+	for _, notSnapshot := range []string{"https://example.com/", "https://web.archive.org/web/", "https://web.archive.org/save/https://example.com"} {
+		if _, err := ParseSnapshotURL(notSnapshot); err == nil {
+			t.Errorf("parsed %v as a snapshot url", notSnapshot)
+		}
+	}
+}
+
+func TestBuildSnapshotURL(t *testing.T) {
+	want := "https://web.archive.org/web/20200101000000id_/https://example.com/"
+	got := BuildSnapshotURL("20200101000000", "https://example.com/", "id_")
+	if got != want {
+		t.Errorf("BuildSnapshotURL = %v, want %v", got, want)
+	}
+
+	s, err := ParseSnapshotURL(got)
+	if err != nil {
+		t.Fatalf("error parsing built url: %v", err)
+	}
+	if s.String() != got {
+		t.Errorf("round trip gave %v, want %v", s.String(), got)
+	}
+}